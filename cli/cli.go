@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"os"
+	"strings"
+
 	"github.com/codegangsta/cli"
 	"github.com/jonnyarnold/fn-go/compiler"
+	"github.com/jonnyarnold/fn-go/compiler/lint"
+	"github.com/jonnyarnold/fn-go/compiler/runtime"
 	"github.com/jonnyarnold/fn-go/repl"
 )
 
@@ -10,6 +15,24 @@ type fnCli struct {
 	app *cli.App
 }
 
+// Disallows defining on blocks from outside (e.g. `block.foo = 1`).
+var strictFlag = cli.BoolFlag{
+	Name:  "strict",
+	Usage: "Disallow adding definitions to existing blocks.",
+}
+
+// Restricts File to paths inside a directory.
+var sandboxFlag = cli.StringFlag{
+	Name:  "sandbox",
+	Usage: "Only allow File to use paths inside `DIR`.",
+}
+
+// Prints the value of the program in the given format.
+var outputFlag = cli.StringFlag{
+	Name:  "output",
+	Usage: "Print the value of the program as text or json.",
+}
+
 // CLI definition.
 func buildCli() fnCli {
 	app := cli.NewApp()
@@ -20,10 +43,82 @@ func buildCli() fnCli {
 		{
 			Name:    "run",
 			Aliases: []string{"r"},
-			Usage:   "Runs the given filename as a a script.",
+			Usage:   "Runs the given filename as a a script; - reads the script from standard input.",
+			// Options after the filename are passed to the script as System.args.
+			SkipArgReorder: true,
+			Flags: []cli.Flag{
+				strictFlag,
+				sandboxFlag,
+				outputFlag,
+			},
 			Action: func(c *cli.Context) {
+				runtime.Strict = c.Bool("strict")
+				runtime.Sandbox = c.String("sandbox")
+				runtime.SetArgs(c.Args().Tail())
+
 				fileName := c.Args().First()
-				compiler.Run(fileName)
+				os.Exit(compiler.Run(fileName, c.String("output")))
+			},
+		},
+
+		{
+			Name:    "eval",
+			Aliases: []string{"e"},
+			Usage:   "Runs the given code and prints its value.",
+			Flags: []cli.Flag{
+				strictFlag,
+				sandboxFlag,
+				outputFlag,
+			},
+			Action: func(c *cli.Context) {
+				runtime.Strict = c.Bool("strict")
+				runtime.Sandbox = c.String("sandbox")
+
+				os.Exit(compiler.Eval(strings.Join(c.Args(), " "), c.String("output")))
+			},
+		},
+
+		{
+			Name:    "check",
+			Aliases: []string{"c"},
+			Usage:   "Checks the types of the given filename without running it.",
+			Action: func(c *cli.Context) {
+				fileName := c.Args().First()
+				if !compiler.Check(fileName) {
+					os.Exit(1)
+				}
+			},
+		},
+
+		{
+			Name:    "lint",
+			Aliases: []string{"l"},
+			Usage:   "Reports likely mistakes in the given filenames.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Output format: text or json.",
+				},
+				cli.StringFlag{
+					Name:  "disable",
+					Usage: "Comma-separated names of rules to skip.",
+				},
+				cli.IntFlag{
+					Name:  "max-nesting",
+					Value: lint.DefaultConfig.MaxNesting,
+					Usage: "The deepest nesting allowed.",
+				},
+			},
+			Action: func(c *cli.Context) {
+				config := lint.Config{MaxNesting: c.Int("max-nesting")}
+				if c.String("disable") != "" {
+					config.Disabled = strings.Split(c.String("disable"), ",")
+				}
+
+				if !compiler.Lint(c.Args(), c.String("format"), config) {
+					os.Exit(1)
+				}
 			},
 		},
 
@@ -31,8 +126,15 @@ func buildCli() fnCli {
 			Name:    "repl",
 			Aliases: []string{"i"},
 			Usage:   "Starts an interactive REPL.",
+			Flags: []cli.Flag{
+				strictFlag,
+				sandboxFlag,
+			},
 			Action: func(c *cli.Context) {
-				repl.Run()
+				runtime.Strict = c.Bool("strict")
+				runtime.Sandbox = c.String("sandbox")
+
+				os.Exit(repl.Run())
 			},
 		},
 	}
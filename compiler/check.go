@@ -0,0 +1,38 @@
+package compiler
+
+import (
+	"fmt"
+	"github.com/jonnyarnold/fn-go/compiler/checker"
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/resolver"
+	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
+	"io/ioutil"
+)
+
+// Checks the names and types of the given file without running it.
+// Returns false if any errors were found; warnings are printed but allowed.
+func Check(fileName string) bool {
+	file, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	expressions, err := Parse(Tokenise(string(file)))
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	diagnostics := resolver.Resolve(expressions)
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic)
+	}
+
+	errs := checker.Check(expressions)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+
+	return !resolver.HasErrors(diagnostics) && len(errs) == 0
+}
@@ -0,0 +1,24 @@
+package checker
+
+// Returns the environment holding the types of the built-in definitions.
+// Built-ins that are not listed are Any.
+func builtins() *environment {
+	env := newEnvironment(nil)
+
+	env.define("nil", monotype(Nil))
+
+	env.define("Boolean", monotype(functionType{Arguments: []Type{Any}, Return: Boolean}))
+	env.define("String", monotype(functionType{Arguments: []Type{Any}, Return: String}))
+	env.define("not", monotype(functionType{Arguments: []Type{Any}, Return: Boolean}))
+	env.define("same", monotype(functionType{Arguments: []Type{Any, Any}, Return: Boolean}))
+	env.define("hash", monotype(functionType{Arguments: []Type{Any}, Return: Number}))
+	env.define("print", monotype(functionType{Arguments: []Type{}, Return: Nil, Flexible: true}))
+	env.define("eprint", monotype(functionType{Arguments: []Type{}, Return: Nil, Flexible: true}))
+	env.define("write", monotype(functionType{Arguments: []Type{}, Return: Nil, Flexible: true}))
+	env.define("readLine", monotype(functionType{Arguments: []Type{}, Return: Any}))
+	env.define("readAll", monotype(functionType{Arguments: []Type{}, Return: String}))
+	env.define("format", monotype(functionType{Arguments: []Type{String}, Return: String, Flexible: true}))
+	env.define("exit", monotype(functionType{Arguments: []Type{Number}, Return: Any}))
+
+	return env
+}
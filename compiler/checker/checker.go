@@ -0,0 +1,313 @@
+// Package checker infers the types of fn programs without running them,
+// reporting type mismatches such as `1 + "a"`.
+//
+// Inference is Hindley-Milner style, with an Any type for values
+// that cannot be known statically (such as blocks). Any is compatible
+// with every type, so untyped code is never rejected for using it.
+package checker
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// A Checker infers the types of expressions.
+// Definitions are remembered between calls to Infer.
+type Checker struct {
+	env          *environment
+	substitution map[int]Type
+	lastVariable int
+	errors       []error
+}
+
+// Returns a Checker knowing only the built-in definitions.
+func New() *Checker {
+	return &Checker{
+		env:          newEnvironment(builtins()),
+		substitution: map[int]Type{},
+	}
+}
+
+// Returns the type errors in the expressions.
+func Check(exprs []Expression) []error {
+	_, errs := New().Infer(exprs)
+	return errs
+}
+
+// Returns a Checker knowing the same definitions,
+// so inference can be tried without keeping what it defines.
+func (c *Checker) Copy() *Checker {
+	env := newEnvironment(c.env.parent)
+	for name, s := range c.env.names {
+		env.define(name, s)
+	}
+
+	substitution := map[int]Type{}
+	for id, t := range c.substitution {
+		substitution[id] = t
+	}
+
+	return &Checker{env: env, substitution: substitution, lastVariable: c.lastVariable}
+}
+
+// Infers the type of the last of the expressions,
+// returning any type errors found on the way.
+func (c *Checker) Infer(exprs []Expression) (Type, []error) {
+	c.errors = []error{}
+	t := c.inferBody(exprs, c.env)
+	return c.display(t), c.errors
+}
+
+// Records a type error in the expression.
+func (c *Checker) report(expr Expression, err error) {
+	c.errors = append(c.errors, errors.New(fmt.Sprintf("Type error in `%s`: %s", expr.String(), err.Error())))
+}
+
+// Returns a new type variable.
+func (c *Checker) fresh() Type {
+	c.lastVariable += 1
+	return typeVariable{id: c.lastVariable}
+}
+
+// Follows the substitution until t is not a bound type variable.
+func (c *Checker) prune(t Type) Type {
+	for {
+		variable, ok := t.(typeVariable)
+		if !ok {
+			return t
+		}
+
+		bound, ok := c.substitution[variable.id]
+		if !ok {
+			return t
+		}
+
+		t = bound
+	}
+}
+
+// Applies the substitution throughout t.
+func (c *Checker) resolve(t Type) Type {
+	t = c.prune(t)
+
+	switch t.(type) {
+	case namedType:
+		named := t.(namedType)
+		parameters := []Type{}
+		for _, parameter := range named.Parameters {
+			parameters = append(parameters, c.resolve(parameter))
+		}
+
+		return namedType{Name: named.Name, Parameters: parameters}
+
+	case functionType:
+		function := t.(functionType)
+		arguments := []Type{}
+		for _, argument := range function.Arguments {
+			arguments = append(arguments, c.resolve(argument))
+		}
+
+		return functionType{Arguments: arguments, Return: c.resolve(function.Return), Flexible: function.Flexible}
+	}
+
+	return t
+}
+
+// Returns t resolved, with its type variables renamed a, b, c...
+func (c *Checker) display(t Type) Type {
+	names := map[int]Type{}
+	return rename(c.resolve(t), func(variable typeVariable) Type {
+		if _, ok := names[variable.id]; !ok {
+			names[variable.id] = namedType{Name: string(rune('a' + len(names)%26))}
+		}
+
+		return names[variable.id]
+	})
+}
+
+// Replaces the type variables in t using the replace function.
+func rename(t Type, replace func(typeVariable) Type) Type {
+	switch t.(type) {
+	case typeVariable:
+		return replace(t.(typeVariable))
+
+	case namedType:
+		named := t.(namedType)
+		parameters := []Type{}
+		for _, parameter := range named.Parameters {
+			parameters = append(parameters, rename(parameter, replace))
+		}
+
+		return namedType{Name: named.Name, Parameters: parameters}
+
+	case functionType:
+		function := t.(functionType)
+		arguments := []Type{}
+		for _, argument := range function.Arguments {
+			arguments = append(arguments, rename(argument, replace))
+		}
+
+		return functionType{Arguments: arguments, Return: rename(function.Return, replace), Flexible: function.Flexible}
+	}
+
+	return t
+}
+
+// Makes the two types equal, binding type variables as needed.
+// Returns an error if they cannot be made equal.
+func (c *Checker) unify(expected Type, actual Type) error {
+	expected, actual = c.prune(expected), c.prune(actual)
+
+	if expected == Any || actual == Any {
+		return nil
+	}
+
+	if variable, ok := expected.(typeVariable); ok {
+		return c.bind(variable, actual)
+	}
+
+	if variable, ok := actual.(typeVariable); ok {
+		return c.bind(variable, expected)
+	}
+
+	mismatch := errors.New(fmt.Sprintf(
+		"expected %s, got %s",
+		c.display(expected).String(),
+		c.display(actual).String(),
+	))
+
+	switch expected.(type) {
+	case namedType:
+		named, other := expected.(namedType), actual
+		otherNamed, ok := other.(namedType)
+		if !ok || named.Name != otherNamed.Name || len(named.Parameters) != len(otherNamed.Parameters) {
+			return mismatch
+		}
+
+		for idx := range named.Parameters {
+			if err := c.unify(named.Parameters[idx], otherNamed.Parameters[idx]); err != nil {
+				return mismatch
+			}
+		}
+
+		return nil
+
+	case functionType:
+		function := expected.(functionType)
+		otherFunction, ok := actual.(functionType)
+		if !ok {
+			return mismatch
+		}
+
+		flexible := function.Flexible || otherFunction.Flexible
+		if !flexible && len(function.Arguments) != len(otherFunction.Arguments) {
+			return mismatch
+		}
+
+		for idx := 0; idx < len(function.Arguments) && idx < len(otherFunction.Arguments); idx++ {
+			if err := c.unify(function.Arguments[idx], otherFunction.Arguments[idx]); err != nil {
+				return mismatch
+			}
+		}
+
+		if err := c.unify(function.Return, otherFunction.Return); err != nil {
+			return mismatch
+		}
+
+		return nil
+	}
+
+	return mismatch
+}
+
+// Binds the type variable to t.
+func (c *Checker) bind(variable typeVariable, t Type) error {
+	if other, ok := t.(typeVariable); ok && other.id == variable.id {
+		return nil
+	}
+
+	if c.occurs(variable, t) {
+		return errors.New(fmt.Sprintf("%s would contain itself", c.display(t).String()))
+	}
+
+	c.substitution[variable.id] = t
+	return nil
+}
+
+// Returns true if the type variable appears in t.
+func (c *Checker) occurs(variable typeVariable, t Type) bool {
+	found := false
+	rename(c.resolve(t), func(other typeVariable) Type {
+		found = found || other.id == variable.id
+		return other
+	})
+
+	return found
+}
+
+// Unifies the types if possible, leaving the substitution unchanged if not.
+// Returns true if the types were unified.
+func (c *Checker) tryUnify(expected Type, actual Type) bool {
+	saved := map[int]Type{}
+	for id, t := range c.substitution {
+		saved[id] = t
+	}
+
+	if err := c.unify(expected, actual); err != nil {
+		c.substitution = saved
+		return false
+	}
+
+	return true
+}
+
+// Returns a copy of the scheme's type, with fresh generalised variables.
+func (c *Checker) instantiate(s scheme) Type {
+	fresh := map[int]Type{}
+	for _, id := range s.variables {
+		fresh[id] = c.fresh()
+	}
+
+	return rename(c.resolve(s.t), func(variable typeVariable) Type {
+		if replacement, ok := fresh[variable.id]; ok {
+			return replacement
+		}
+
+		return variable
+	})
+}
+
+// Returns a scheme generalising the type variables of t
+// that are not used by the environment.
+func (c *Checker) generalise(env *environment, t Type) scheme {
+	inEnvironment := map[int]bool{}
+	for current := env; current != nil; current = current.parent {
+		for _, s := range current.names {
+			for _, id := range c.variablesOf(s.t) {
+				inEnvironment[id] = true
+			}
+		}
+	}
+
+	variables := []int{}
+	for _, id := range c.variablesOf(t) {
+		if !inEnvironment[id] {
+			variables = append(variables, id)
+		}
+	}
+
+	return scheme{variables: variables, t: t}
+}
+
+// Returns the ids of the unbound type variables in t.
+func (c *Checker) variablesOf(t Type) []int {
+	ids := []int{}
+	rename(c.resolve(t), func(variable typeVariable) Type {
+		ids = append(ids, variable.id)
+		return variable
+	})
+
+	return ids
+}
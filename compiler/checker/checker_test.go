@@ -0,0 +1,211 @@
+package checker
+
+import (
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/tokeniser"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Returns the Expressions for the given code.
+func exprsFor(code string) []Expression {
+	tokens := tokeniser.Tokenise(code)
+	exprs, err := Parse(tokens)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return exprs
+}
+
+// Returns the type of the last expression and any errors.
+func typeOf(code string) (string, []error) {
+	t, errs := New().Infer(exprsFor(code))
+	return t.String(), errs
+}
+
+func TestChecker(t *testing.T) {
+	Convey("Literals", t, func() {
+
+		Convey("have their own types", func() {
+			t, errs := typeOf("1")
+			So(t, ShouldEqual, "Number")
+			So(errs, ShouldBeEmpty)
+
+			t, _ = typeOf("\"a\"")
+			So(t, ShouldEqual, "String")
+
+			t, _ = typeOf("true")
+			So(t, ShouldEqual, "Boolean")
+		})
+
+	})
+
+	Convey("Operators", t, func() {
+
+		Convey("take numbers", func() {
+			t, errs := typeOf("1 + 2")
+			So(t, ShouldEqual, "Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("report a mismatch for other types", func() {
+			_, errs := typeOf("1 + \"a\"")
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Error(), ShouldContainSubstring, "expected Number, got String")
+		})
+
+		Convey("report types that do not implement them", func() {
+			_, errs := typeOf("\"a\" * 2")
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Error(), ShouldContainSubstring, "String does not implement *")
+		})
+
+		Convey("allow blocks, which may define them", func() {
+			_, errs := typeOf("v = { x = 1 }; v + \"a\"")
+			So(errs, ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Functions", t, func() {
+
+		Convey("have their argument and return types inferred", func() {
+			t, errs := typeOf("(a, b) { a + b }")
+			So(t, ShouldEqual, "(Number, Number) -> Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("are generic where they can be", func() {
+			t, errs := typeOf("id = (x) { x }; id")
+			So(t, ShouldEqual, "(a) -> a")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("can be used at different types", func() {
+			t, errs := typeOf("id = (x) { x }; id(\"a\"); id(1)")
+			So(t, ShouldEqual, "Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("report mismatched arguments", func() {
+			_, errs := typeOf("add = (a, b) { a + b }; add(1, \"two\")")
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Error(), ShouldContainSubstring, "argument 2")
+		})
+
+		Convey("report too many arguments", func() {
+			_, errs := typeOf("add = (a, b) { a + b }; add(1, 2, 3)")
+			So(len(errs), ShouldEqual, 1)
+		})
+
+		Convey("are partially applied with too few arguments", func() {
+			t, errs := typeOf("add = (a, b) { a + b }; add(1)")
+			So(t, ShouldEqual, "(Number) -> Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("can be recursive", func() {
+			t, errs := typeOf("fact = (n) { when { n eq 0 { 1 } true { n * fact(n - 1) } } }; fact")
+			So(t, ShouldEqual, "(Number) -> Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Annotations", t, func() {
+
+		Convey("set the types of arguments", func() {
+			t, errs := typeOf("(a: String) { a }")
+			So(t, ShouldEqual, "(String) -> String")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("report return types that do not match", func() {
+			_, errs := typeOf("(a) -> String { a * 2 }")
+			So(len(errs), ShouldEqual, 1)
+		})
+
+		Convey("report definitions that do not match", func() {
+			_, errs := typeOf("x: String = 1")
+			So(len(errs), ShouldEqual, 1)
+		})
+
+		Convey("accept Any", func() {
+			_, errs := typeOf("x: Any = 1; y: Any = \"a\"")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("accept function types", func() {
+			_, errs := typeOf("apply = (f: (Number) -> Number, x) { f(x) }; apply((s: String) { s }, 1)")
+			So(len(errs), ShouldEqual, 1)
+		})
+
+	})
+
+	Convey("Conditionals", t, func() {
+
+		Convey("have the type of their branches", func() {
+			t, errs := typeOf("when { true { 1 } false { 2 } }")
+			So(t, ShouldEqual, "Number")
+			So(errs, ShouldBeEmpty)
+		})
+
+		Convey("are Any if their branches differ", func() {
+			t, errs := typeOf("when { true { 1 } false { \"a\" } }")
+			So(t, ShouldEqual, "Any")
+			So(errs, ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Lists", t, func() {
+
+		Convey("have the type of their items", func() {
+			t, _ := typeOf("List(1, 2)")
+			So(t, ShouldEqual, "List(Number)")
+		})
+
+		Convey("may hold different types", func() {
+			t, errs := typeOf("List(1, \"a\")")
+			So(t, ShouldEqual, "List(Any)")
+			So(errs, ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Copies", t, func() {
+
+		Convey("know the definitions made before copying", func() {
+			c := New()
+			c.Infer(exprsFor("x = 1"))
+			t, _ := c.Copy().Infer(exprsFor("x"))
+			So(t.String(), ShouldEqual, "Number")
+		})
+
+		Convey("do not add their definitions to the original", func() {
+			c := New()
+			c.Copy().Infer(exprsFor("y = 1"))
+			t, _ := c.Infer(exprsFor("y"))
+			So(t.String(), ShouldEqual, "Any")
+		})
+
+	})
+
+	Convey("Attribute calls", t, func() {
+
+		Convey("check the arguments of every call in a chain", func() {
+			_, errs := typeOf("b = { f = (a) { a } }; b.f(1).g(1 + \"a\")")
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Error(), ShouldContainSubstring, "expected Number, got String")
+		})
+
+	})
+
+	Convey("Unknown names are Any", t, func() {
+		t, errs := typeOf("notDefined")
+		So(t, ShouldEqual, "Any")
+		So(errs, ShouldBeEmpty)
+	})
+}
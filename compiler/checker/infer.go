@@ -0,0 +1,473 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// Infers the type of each expression in turn, returning the type of the last.
+// An empty body is Nil.
+func (c *Checker) inferBody(exprs []Expression, env *environment) Type {
+	var t Type = Nil
+	for _, expr := range exprs {
+		t = c.infer(expr, env)
+	}
+
+	return t
+}
+
+// Infers the type of the expression.
+func (c *Checker) infer(expr Expression, env *environment) Type {
+	switch expr.(type) {
+	case NumberExpression:
+		return Number
+	case StringExpression:
+		return String
+	case BooleanExpression:
+		return Boolean
+
+	case IdentifierExpression:
+		s, ok := env.lookup(expr.(IdentifierExpression).Name)
+		if !ok {
+			return Any
+		}
+
+		return c.instantiate(s)
+
+	case FunctionPrototypeExpression:
+		return c.inferFunction(expr.(FunctionPrototypeExpression), env)
+
+	case BlockExpression:
+		c.inferBody(expr.(BlockExpression).Body, newEnvironment(env))
+		return Any
+
+	case ExtendExpression:
+		extend := expr.(ExtendExpression)
+		c.infer(extend.Base, env)
+		c.inferBody(extend.Body.Body, newEnvironment(env))
+		return Any
+
+	case ConditionalExpression:
+		return c.inferConditional(expr.(ConditionalExpression), env)
+
+	case FunctionCallExpression:
+		return c.inferCall(expr.(FunctionCallExpression), env)
+	}
+
+	return Any
+}
+
+// Infers the type of a function definition.
+func (c *Checker) inferFunction(expr FunctionPrototypeExpression, env *environment) Type {
+	fnEnv := newEnvironment(env)
+	function := functionType{Arguments: []Type{}}
+
+	for _, arg := range expr.Arguments {
+		argType := c.fresh()
+		if arg.Type != nil {
+			argType = toType(arg.Type)
+		} else if arg.Rest {
+			argType = listOf(c.fresh())
+		}
+
+		if arg.Default != nil {
+			if err := c.unify(argType, c.infer(arg.Default, env)); err != nil {
+				c.report(arg, err)
+			}
+		}
+
+		c.definePattern(arg.Pattern, argType, fnEnv)
+
+		if arg.Rest || arg.Default != nil {
+			function.Flexible = true
+		}
+
+		if !arg.Rest {
+			function.Arguments = append(function.Arguments, argType)
+		}
+	}
+
+	function.Return = c.inferBody(expr.Body.Body, fnEnv)
+
+	if expr.ReturnType != nil {
+		returnType := toType(expr.ReturnType)
+		if err := c.unify(returnType, function.Return); err != nil {
+			c.report(expr.ReturnType, errors.New(fmt.Sprintf("function returns the wrong type: %s", err.Error())))
+		}
+
+		function.Return = returnType
+	}
+
+	return function
+}
+
+// Defines the names in the pattern.
+// Only identifiers take the type; names in destructuring patterns are Any.
+func (c *Checker) definePattern(pattern Expression, t Type, env *environment) {
+	switch pattern.(type) {
+	case IdentifierExpression:
+		env.define(pattern.(IdentifierExpression).Name, monotype(t))
+
+	case TypedPatternExpression:
+		c.definePattern(pattern.(TypedPatternExpression).Pattern, t, env)
+
+	case ListPatternExpression:
+		for _, item := range pattern.(ListPatternExpression).Items {
+			c.definePattern(item, Any, env)
+		}
+
+	case BlockPatternExpression:
+		for _, name := range pattern.(BlockPatternExpression).Names {
+			env.define(name.Name, monotype(Any))
+		}
+	}
+}
+
+// Infers the type of a conditional.
+// If every branch has the same type, the conditional has that type; otherwise it is Any.
+func (c *Checker) inferConditional(expr ConditionalExpression, env *environment) Type {
+	if expr.Subject != nil {
+		c.infer(expr.Subject, env)
+	}
+
+	branchTypes := []Type{}
+	for _, branch := range expr.Branches {
+		branchEnv := env
+		if pattern, ok := branch.Condition.(VariantPatternExpression); ok {
+			// Variant fields are defined only within the branch.
+			branchEnv = newEnvironment(env)
+			for _, field := range pattern.Fields {
+				c.definePattern(field, Any, branchEnv)
+			}
+		} else {
+			c.infer(branch.Condition, env)
+		}
+
+		branchTypes = append(branchTypes, c.inferBody(branch.Body.Body, branchEnv))
+	}
+
+	return c.common(branchTypes)
+}
+
+// Returns the type of all of the types, or Any if they differ.
+// Types that are not yet known are not bound to each other,
+// as the values may legitimately differ at runtime.
+func (c *Checker) common(types []Type) Type {
+	if len(types) == 0 {
+		return Nil
+	}
+
+	for _, t := range types {
+		if len(c.variablesOf(t)) > 0 {
+			return Any
+		}
+	}
+
+	for _, t := range types[1:] {
+		if !c.tryUnify(types[0], t) {
+			return Any
+		}
+	}
+
+	return types[0]
+}
+
+// Infers the type of a function call, including infix operators.
+func (c *Checker) inferCall(expr FunctionCallExpression, env *environment) Type {
+	id, args := expr.Identifier.Name, expr.Arguments
+
+	switch id {
+	case "=":
+		return c.inferDefinition(args[0], args[1], env)
+
+	case ".":
+		// Blocks are Any, but calls such as `x.foo(a)` take their arguments from this scope.
+		c.infer(args[0], env)
+		c.inferChain(args[1], env)
+		return Any
+
+	case "with", "and", "or":
+		c.infer(args[0], env)
+		c.infer(args[1], env)
+		return Any
+
+	case "eq":
+		c.infer(args[0], env)
+		c.infer(args[1], env)
+		return Boolean
+
+	case "+", "-", "*", "/":
+		return c.inferOperator(expr, Number, env)
+
+	case "lessThan", "moreThan":
+		return c.inferOperator(expr, Boolean, env)
+
+	case "|>":
+		value, function := c.infer(args[0], env), c.infer(args[1], env)
+		result := c.fresh()
+		if err := c.unify(function, functionType{Arguments: []Type{value}, Return: result}); err != nil {
+			c.report(expr, err)
+			return Any
+		}
+
+		return result
+
+	case "List":
+		items := c.inferArgs(args, env)
+		if len(items) == 0 {
+			return listOf(c.fresh())
+		}
+
+		return listOf(c.common(items))
+
+	case "Union":
+		// Union defines its variants in the scope.
+		for _, arg := range args {
+			switch arg.(type) {
+			case IdentifierExpression:
+				env.define(arg.(IdentifierExpression).Name, monotype(Any))
+			case FunctionCallExpression:
+				env.define(arg.(FunctionCallExpression).Identifier.Name, monotype(Any))
+			}
+		}
+
+		return Any
+
+	case "import!", "import":
+		return Any
+	}
+
+	return c.inferFunctionCall(expr, env)
+}
+
+// Infers the type of an operator such as `+`.
+// Numbers take numbers; Any and blocks may define the operator themselves.
+func (c *Checker) inferOperator(expr FunctionCallExpression, result Type, env *environment) Type {
+	op := expr.Identifier.Name
+	lhs, rhs := c.infer(expr.Arguments[0], env), c.infer(expr.Arguments[1], env)
+
+	switch c.prune(lhs).(type) {
+	case anyType:
+		return Any
+
+	case typeVariable:
+		// As in most languages, an unknown operand is assumed to be a number.
+		c.unify(Number, lhs)
+
+	case namedType:
+		if c.prune(lhs).(namedType).Name != "Number" {
+			c.report(expr, errors.New(fmt.Sprintf("%s does not implement %s", c.display(lhs).String(), op)))
+			return Any
+		}
+
+	default:
+		c.report(expr, errors.New(fmt.Sprintf("%s does not implement %s", c.display(lhs).String(), op)))
+		return Any
+	}
+
+	if err := c.unify(Number, rhs); err != nil {
+		c.report(expr, err)
+		return Any
+	}
+
+	return result
+}
+
+// Infers the type of the arguments of a call.
+func (c *Checker) inferArgs(args []Expression, env *environment) []Type {
+	types := []Type{}
+	for _, arg := range args {
+		if named, ok := arg.(NamedArgumentExpression); ok {
+			types = append(types, c.infer(named.Value, env))
+		} else {
+			types = append(types, c.infer(arg, env))
+		}
+	}
+
+	return types
+}
+
+// Infers the arguments of the calls in a chain such as `x.foo(a).bar(b)`,
+// which all come from this scope.
+func (c *Checker) inferChain(expr Expression, env *environment) {
+	call, ok := expr.(FunctionCallExpression)
+	if !ok {
+		return
+	}
+
+	if call.Identifier.Name == "." {
+		c.inferChain(call.Arguments[0], env)
+		c.inferChain(call.Arguments[1], env)
+	} else if !IsOperator(call.Identifier.Name) {
+		c.inferArgs(call.Arguments, env)
+	}
+}
+
+// Infers the type of a call to a named function.
+func (c *Checker) inferFunctionCall(expr FunctionCallExpression, env *environment) Type {
+	argTypes := c.inferArgs(expr.Arguments, env)
+
+	s, ok := env.lookup(expr.Identifier.Name)
+	if !ok {
+		return Any
+	}
+
+	// Named arguments can be given in any order, so only positional arguments are checked.
+	positional := []Type{}
+	for idx, arg := range expr.Arguments {
+		if _, isNamed := arg.(NamedArgumentExpression); isNamed {
+			break
+		}
+
+		positional = append(positional, argTypes[idx])
+	}
+	hasNamed := len(positional) < len(argTypes)
+
+	callee := c.prune(c.instantiate(s))
+	switch callee.(type) {
+	case typeVariable:
+		if hasNamed {
+			return Any
+		}
+
+		result := c.fresh()
+		if err := c.unify(callee, functionType{Arguments: positional, Return: result}); err != nil {
+			c.report(expr, err)
+			return Any
+		}
+
+		return result
+
+	case functionType:
+		function := callee.(functionType)
+		for idx := 0; idx < len(positional) && idx < len(function.Arguments); idx++ {
+			if err := c.unify(function.Arguments[idx], positional[idx]); err != nil {
+				c.report(expr, errors.New(fmt.Sprintf("argument %d: %s", idx+1, err.Error())))
+				return Any
+			}
+		}
+
+		if function.Flexible || hasNamed {
+			return function.Return
+		}
+
+		if len(positional) > len(function.Arguments) {
+			c.report(expr, errors.New(fmt.Sprintf(
+				"%s takes %d arguments, got %d",
+				expr.Identifier.Name,
+				len(function.Arguments),
+				len(positional),
+			)))
+			return Any
+		}
+
+		// Calls with too few arguments are partially applied.
+		if len(positional) < len(function.Arguments) {
+			return functionType{Arguments: function.Arguments[len(positional):], Return: function.Return}
+		}
+
+		return function.Return
+
+	case namedType:
+		named := callee.(namedType)
+		if named.Name == "List" && len(positional) == 1 {
+			if err := c.unify(Number, positional[0]); err != nil {
+				c.report(expr, err)
+			}
+
+			return named.Parameters[0]
+		}
+
+		c.report(expr, errors.New(fmt.Sprintf("%s is %s, which cannot be called", expr.Identifier.Name, c.display(named).String())))
+		return Any
+	}
+
+	return Any
+}
+
+// Infers the type of a definition, defining the names in the pattern.
+// Definitions return the scope, which is Any.
+func (c *Checker) inferDefinition(pattern Expression, value Expression, env *environment) Type {
+	var annotation Type
+	if typed, ok := pattern.(TypedPatternExpression); ok {
+		annotation = toType(typed.Type)
+		pattern = typed.Pattern
+	}
+
+	id, isIdentifier := pattern.(IdentifierExpression)
+
+	// Functions may call themselves, so are defined before their bodies are checked.
+	_, isFunction := value.(FunctionPrototypeExpression)
+	if isIdentifier && isFunction {
+		self := c.fresh()
+		if annotation != nil {
+			self = annotation
+		}
+
+		env.define(id.Name, monotype(self))
+	}
+
+	t := c.infer(value, env)
+
+	if annotation != nil {
+		if err := c.unify(annotation, t); err != nil {
+			c.report(value, errors.New(fmt.Sprintf("%s is declared as %s: %s", id.Name, c.display(annotation).String(), err.Error())))
+		}
+
+		t = annotation
+	}
+
+	if !isIdentifier {
+		c.definePattern(pattern, Any, env)
+		return Any
+	}
+
+	if isFunction {
+		self, _ := env.lookup(id.Name)
+		if err := c.unify(self.t, t); err != nil {
+			c.report(value, err)
+		}
+
+		delete(env.names, id.Name)
+	}
+
+	env.define(id.Name, c.generalise(env, t))
+	return Any
+}
+
+// Converts a type annotation into a type.
+// Names other than the built-in types are taken as they are, e.g. record names.
+func toType(expr Expression) Type {
+	switch expr.(type) {
+	case TypeExpression:
+		typeExpr := expr.(TypeExpression)
+		if typeExpr.Name == "Any" {
+			return Any
+		}
+
+		parameters := []Type{}
+		for _, parameter := range typeExpr.Parameters {
+			parameters = append(parameters, toType(parameter))
+		}
+
+		// An unparameterised List may hold anything.
+		if typeExpr.Name == "List" && len(parameters) == 0 {
+			parameters = []Type{Any}
+		}
+
+		return namedType{Name: typeExpr.Name, Parameters: parameters}
+
+	case FunctionTypeExpression:
+		functionExpr := expr.(FunctionTypeExpression)
+		arguments := []Type{}
+		for _, argument := range functionExpr.Arguments {
+			arguments = append(arguments, toType(argument))
+		}
+
+		return functionType{Arguments: arguments, Return: toType(functionExpr.Return)}
+	}
+
+	return Any
+}
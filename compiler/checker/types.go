@@ -0,0 +1,114 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Type is the static type of an expression.
+type Type interface {
+	String() string
+}
+
+// A typeVariable stands for a type that is not yet known.
+type typeVariable struct {
+	id int
+}
+
+func (tv typeVariable) String() string {
+	return fmt.Sprintf("t%d", tv.id)
+}
+
+// A namedType is a concrete type such as `Number` or `List(String)`.
+type namedType struct {
+	Name       string
+	Parameters []Type
+}
+
+func (nt namedType) String() string {
+	if len(nt.Parameters) == 0 {
+		return nt.Name
+	}
+
+	return fmt.Sprintf("%s(%s)", nt.Name, joinTypes(nt.Parameters))
+}
+
+// A functionType is the type of a function.
+// Flexible functions have defaults or rest arguments,
+// so can be called with a different number of arguments.
+type functionType struct {
+	Arguments []Type
+	Return    Type
+	Flexible  bool
+}
+
+func (ft functionType) String() string {
+	return fmt.Sprintf("(%s) -> %s", joinTypes(ft.Arguments), ft.Return.String())
+}
+
+// The anyType is compatible with every type.
+// Values whose type cannot be known statically, such as blocks, are Any.
+type anyType struct{}
+
+func (at anyType) String() string {
+	return "Any"
+}
+
+var (
+	Any     Type = anyType{}
+	Number  Type = namedType{Name: "Number"}
+	String  Type = namedType{Name: "String"}
+	Boolean Type = namedType{Name: "Boolean"}
+	Nil     Type = namedType{Name: "Nil"}
+)
+
+// Returns the type of a list of the given items.
+func listOf(item Type) Type {
+	return namedType{Name: "List", Parameters: []Type{item}}
+}
+
+func joinTypes(types []Type) string {
+	strs := []string{}
+	for _, t := range types {
+		strs = append(strs, t.String())
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+// A scheme is a type that may be used at many types,
+// such as the type `(a) -> a` of the identity function.
+type scheme struct {
+	variables []int // The ids of the generalised type variables.
+	t         Type
+}
+
+// A mapping of names to their types.
+type environment struct {
+	parent *environment
+	names  map[string]scheme
+}
+
+func newEnvironment(parent *environment) *environment {
+	return &environment{parent: parent, names: map[string]scheme{}}
+}
+
+// Returns the type of the name, and false if it is not defined.
+func (env *environment) lookup(name string) (scheme, bool) {
+	for current := env; current != nil; current = current.parent {
+		if s, ok := current.names[name]; ok {
+			return s, true
+		}
+	}
+
+	return scheme{}, false
+}
+
+func (env *environment) define(name string, s scheme) {
+	env.names[name] = s
+}
+
+// Returns a scheme for a type that is not generalised.
+func monotype(t Type) scheme {
+	return scheme{t: t}
+}
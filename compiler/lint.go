@@ -0,0 +1,65 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jonnyarnold/fn-go/compiler/lint"
+	"io/ioutil"
+	"os"
+)
+
+// A lint problem in a file, as output in JSON.
+type fileProblem struct {
+	File string `json:"file"`
+	lint.Problem
+}
+
+// Lints the given files, printing problems as text or JSON.
+// Files that cannot be read or parsed are reported on stderr,
+// so the JSON output stays valid.
+// Returns false if any problems were found.
+func Lint(fileNames []string, format string, config lint.Config) bool {
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown lint format %s\n", format)
+		return false
+	}
+
+	if err := lint.CheckConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	problems := []fileProblem{}
+	ok := true
+
+	for _, fileName := range fileNames {
+		file, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+			continue
+		}
+
+		fileProblems, err := lint.Lint(string(file), config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", fileName, err)
+			ok = false
+			continue
+		}
+
+		for _, problem := range fileProblems {
+			problems = append(problems, fileProblem{File: fileName, Problem: problem})
+		}
+	}
+
+	if format == "json" {
+		output, _ := json.MarshalIndent(problems, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		for _, problem := range problems {
+			fmt.Printf("%s:%s\n", problem.File, problem.Problem)
+		}
+	}
+
+	return ok && len(problems) == 0
+}
@@ -0,0 +1,192 @@
+// Package lint finds likely mistakes in fn programs that still run,
+// such as unused definitions and unreachable branches.
+//
+// Each check is a Rule, which can be disabled by name.
+// Problems can also be ignored with a comment on or above their line,
+// or above the top-level statement containing them:
+//
+//	# fn:ignore                  ignores every rule
+//	# fn:ignore unused, nesting   ignores the named rules
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
+)
+
+// A Problem found by a rule.
+// Line is the line of the expression with the problem.
+type Problem struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d: %s (%s)", p.Line, p.Message, p.Rule)
+}
+
+// A Rule checks a program, calling report for each problem it finds.
+type Rule interface {
+	Name() string
+	Check(program []Statement, report func(line int, message string))
+}
+
+// Options for linting.
+type Config struct {
+	Disabled   []string // Names of rules that are not run.
+	MaxNesting int      // The deepest nesting allowed before a problem is reported.
+}
+
+var DefaultConfig = Config{MaxNesting: 4}
+
+// Returns every rule, configured by the config.
+func Rules(config Config) []Rule {
+	return []Rule{
+		unusedRule{},
+		unreachableRule{},
+		catchAllRule{},
+		redefinitionRule{},
+		suspiciousEqRule{},
+		nestingRule{max: config.MaxNesting},
+	}
+}
+
+// Returns the problems found in the source code by the enabled rules,
+// sorted by line.
+func Lint(source string, config Config) ([]Problem, error) {
+	rules, err := enabledRules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := ParseStatements(Tokenise(source))
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := ignoreComments(source)
+	problems := []Problem{}
+	for _, rule := range rules {
+		name := rule.Name()
+		rule.Check(program, func(line int, message string) {
+			if !isIgnored(ignored, line, name) && !isIgnored(ignored, statementLine(program, line), name) {
+				problems = append(problems, Problem{Rule: name, Line: line, Message: message})
+			}
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+
+	return problems, nil
+}
+
+// Returns an error if the config disables a rule that does not exist.
+func CheckConfig(config Config) error {
+	rules := Rules(config)
+	for _, name := range config.Disabled {
+		if !isRuleName(rules, name) {
+			return errors.New(fmt.Sprintf("Unknown lint rule %s", name))
+		}
+	}
+
+	return nil
+}
+
+// Returns the rules not disabled by the config.
+func enabledRules(config Config) ([]Rule, error) {
+	if err := CheckConfig(config); err != nil {
+		return nil, err
+	}
+
+	disabled := map[string]bool{}
+	for _, name := range config.Disabled {
+		disabled[name] = true
+	}
+
+	rules := Rules(config)
+
+	enabled := []Rule{}
+	for _, rule := range rules {
+		if !disabled[rule.Name()] {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	return enabled, nil
+}
+
+func isRuleName(rules []Rule, name string) bool {
+	for _, rule := range rules {
+		if rule.Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+var ignorePattern = regexp.MustCompile(`#\s*fn:ignore\b(.*)$`)
+
+// Returns the rules ignored by comments, keyed by line.
+// An empty list ignores every rule.
+func ignoreComments(source string) map[int][]string {
+	ignored := map[int][]string{}
+
+	for idx, line := range strings.Split(source, "\n") {
+		match := ignorePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		ignored[idx+1] = strings.FieldsFunc(match[1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})
+	}
+
+	return ignored
+}
+
+// Returns the line of the top-level statement containing the line.
+func statementLine(program []Statement, line int) int {
+	start := line
+	for _, statement := range program {
+		if statement.Line > line {
+			break
+		}
+
+		start = statement.Line
+	}
+
+	return start
+}
+
+// Returns true if a comment on the line, or the line above, ignores the rule.
+func isIgnored(ignored map[int][]string, line int, rule string) bool {
+	for _, commentLine := range []int{line, line - 1} {
+		rules, ok := ignored[commentLine]
+		if !ok {
+			continue
+		}
+
+		if len(rules) == 0 {
+			return true
+		}
+
+		for _, name := range rules {
+			if name == rule {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package lint
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+// Returns the problems in the code, as strings.
+func problemsFor(code string, config Config) []string {
+	problems, err := Lint(code, config)
+	if err != nil {
+		panic(err)
+	}
+
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	return messages
+}
+
+func lint(code string) []string {
+	return problemsFor(code, DefaultConfig)
+}
+
+func TestLint(t *testing.T) {
+	Convey("unused", t, func() {
+
+		Convey("reports definitions in functions that are never used", func() {
+			So(lint("f = (a) {\n  x = 1\n  a\n}"), ShouldResemble, []string{
+				"2: x is defined but never used (unused)",
+			})
+		})
+
+		Convey("counts uses in nested functions", func() {
+			So(lint("f = () { x = 1; () { x } }"), ShouldBeEmpty)
+		})
+
+		Convey("does not report top-level definitions or names starting with _", func() {
+			So(lint("x = 1; f = () { _y = 1; 2 }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("unreachable", t, func() {
+
+		Convey("reports branches after a true branch", func() {
+			So(lint("when { true { 1 } false { 2 } }"), ShouldResemble, []string{
+				"1: when branch false is unreachable after a true branch (unreachable)",
+			})
+		})
+
+	})
+
+	Convey("catch-all", t, func() {
+
+		Convey("reports a when without a true branch", func() {
+			So(lint("x = 1; when { x { 1 } }"), ShouldResemble, []string{
+				"1: when has no true branch, so fails if no condition matches (catch-all)",
+			})
+		})
+
+		Convey("does not report matches on a union", func() {
+			So(lint("S = Union(A, B); s = A; when s { A { 1 } B { 2 } }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("redefinition", t, func() {
+
+		Convey("reports names defined twice in the same scope", func() {
+			So(lint("x = 1\nx = 2"), ShouldResemble, []string{"2: x is already defined (redefinition)"})
+			So(lint("f = (a) { a = 1; a }"), ShouldResemble, []string{"1: a is already defined (redefinition)"})
+		})
+
+		Convey("does not report names in separate scopes or branches", func() {
+			So(lint("x = 1; b = { x = 2 }; when { false { y = 1 } true { y = 2 } }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("suspicious-eq", t, func() {
+
+		Convey("reports eq between literals of different kinds", func() {
+			So(lint("1 eq \"1\""), ShouldResemble, []string{
+				"1: eq compares a Number with a String, so is always false (suspicious-eq)",
+			})
+		})
+
+		Convey("does not report literals of the same kind", func() {
+			So(lint("1 eq 2"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("nesting", t, func() {
+
+		Convey("reports statements nested too deeply", func() {
+			So(problemsFor("a = { b = { c = { 1 } } }", Config{MaxNesting: 2}), ShouldResemble, []string{
+				"1: nested 3 levels deep (the maximum is 2) (nesting)",
+			})
+		})
+
+	})
+
+	Convey("Problems in functions", t, func() {
+
+		Convey("are reported on their own line", func() {
+			code := "f = (a) {\n  x = 1\n  1 eq \"a\"\n  when {\n    a { 1 }\n    true { 2 }\n    false { 3 }\n  }\n}"
+			So(lint(code), ShouldResemble, []string{
+				"2: x is defined but never used (unused)",
+				"3: eq compares a Number with a String, so is always false (suspicious-eq)",
+				"7: when branch false is unreachable after a true branch (unreachable)",
+			})
+		})
+
+	})
+
+	Convey("Rules", t, func() {
+
+		Convey("can be disabled", func() {
+			So(problemsFor("x = 1; x = 2", Config{Disabled: []string{"redefinition"}}), ShouldBeEmpty)
+		})
+
+		Convey("must exist to be disabled", func() {
+			_, err := Lint("1", Config{Disabled: []string{"bogus"}})
+			So(err, ShouldNotBeNil)
+
+			So(CheckConfig(Config{Disabled: []string{"bogus"}}), ShouldNotBeNil)
+			So(CheckConfig(Config{Disabled: []string{"unused"}}), ShouldBeNil)
+		})
+
+	})
+
+	Convey("fn:ignore comments", t, func() {
+
+		Convey("ignore every rule on their line or the next", func() {
+			So(lint("x = 1\nx = 2 # fn:ignore"), ShouldBeEmpty)
+			So(lint("x = 1\n# fn:ignore\nx = 2"), ShouldBeEmpty)
+		})
+
+		Convey("can ignore only the named rules", func() {
+			So(lint("x = 1\n# fn:ignore unused, nesting\nx = 2"), ShouldResemble, []string{
+				"3: x is already defined (redefinition)",
+			})
+			So(lint("x = 1\n# fn:ignore unused, redefinition\nx = 2"), ShouldBeEmpty)
+		})
+
+		Convey("ignore problems inside a function from above it or above the problem", func() {
+			code := "f = (a) {\n  # fn:ignore catch-all\n  when {\n    a { 1 }\n  }\n}"
+			So(lint(code), ShouldBeEmpty)
+			So(lint("# fn:ignore catch-all\n"+strings.Replace(code, "# fn:ignore catch-all", "", 1)), ShouldBeEmpty)
+			So(lint(strings.Replace(code, "# fn:ignore catch-all", "", 1)), ShouldResemble, []string{
+				"3: when has no true branch, so fails if no condition matches (catch-all)",
+			})
+		})
+
+	})
+}
@@ -0,0 +1,228 @@
+package lint
+
+import (
+	"fmt"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/resolver"
+)
+
+// Reports definitions in functions that are never used.
+// Top-level definitions and attributes may be used from outside, so are not reported.
+type unusedRule struct{}
+
+func (unusedRule) Name() string { return "unused" }
+
+func (unusedRule) Check(program []Statement, report func(int, string)) {
+	reportResolved(program, resolver.Unused, report)
+}
+
+// Reports branches of a when that follow a `true` branch, so can never run.
+type unreachableRule struct{}
+
+func (unreachableRule) Name() string { return "unreachable" }
+
+func (unreachableRule) Check(program []Statement, report func(int, string)) {
+	for _, statement := range program {
+		walk(statement.Expression, func(expr Expression) {
+			conditional, ok := expr.(ConditionalExpression)
+			if !ok {
+				return
+			}
+
+			for idx, branch := range conditional.Branches {
+				if isTrue(branch.Condition) && idx+1 < len(conditional.Branches) {
+					unreachable := conditional.Branches[idx+1]
+					report(unreachable.Line, fmt.Sprintf(
+						"when branch %s is unreachable after a true branch",
+						unreachable.Condition.String(),
+					))
+					break
+				}
+			}
+		})
+	}
+}
+
+// Reports a when without a `true` branch, which fails if no condition matches.
+// Matches on a union are checked for every variant when run, so are not reported.
+type catchAllRule struct{}
+
+func (catchAllRule) Name() string { return "catch-all" }
+
+func (catchAllRule) Check(program []Statement, report func(int, string)) {
+	for _, statement := range program {
+		walk(statement.Expression, func(expr Expression) {
+			conditional, ok := expr.(ConditionalExpression)
+			if !ok || conditional.Subject != nil {
+				return
+			}
+
+			for _, branch := range conditional.Branches {
+				if isTrue(branch.Condition) {
+					return
+				}
+			}
+
+			report(conditional.Line, "when has no true branch, so fails if no condition matches")
+		})
+	}
+}
+
+// Reports names defined twice in the same scope, which fails when run.
+type redefinitionRule struct{}
+
+func (redefinitionRule) Name() string { return "redefinition" }
+
+func (redefinitionRule) Check(program []Statement, report func(int, string)) {
+	reportResolved(program, resolver.Redefined, report)
+}
+
+// Reports the problems of the kind found by the resolver,
+// so fn lint and fn check agree on them.
+func reportResolved(program []Statement, kind resolver.Kind, report func(int, string)) {
+	exprs := []Expression{}
+	for _, statement := range program {
+		exprs = append(exprs, statement.Expression)
+	}
+
+	for _, diagnostic := range resolver.Resolve(exprs) {
+		if diagnostic.Kind == kind {
+			report(diagnostic.Line, diagnostic.Message)
+		}
+	}
+}
+
+// Reports `eq` between literals of different kinds, which is always false.
+type suspiciousEqRule struct{}
+
+func (suspiciousEqRule) Name() string { return "suspicious-eq" }
+
+func (suspiciousEqRule) Check(program []Statement, report func(int, string)) {
+	for _, statement := range program {
+		walk(statement.Expression, func(expr Expression) {
+			call, ok := expr.(FunctionCallExpression)
+			if !ok || call.Identifier.Name != "eq" || len(call.Arguments) != 2 {
+				return
+			}
+
+			left, right := literalKind(call.Arguments[0]), literalKind(call.Arguments[1])
+			if left != "" && right != "" && left != right {
+				report(call.Line, fmt.Sprintf("eq compares a %s with a %s, so is always false", left, right))
+			}
+		})
+	}
+}
+
+// Reports statements nested more deeply than the maximum.
+type nestingRule struct {
+	max int
+}
+
+func (nestingRule) Name() string { return "nesting" }
+
+func (rule nestingRule) Check(program []Statement, report func(int, string)) {
+	for _, statement := range program {
+		if depth := nestingDepth(statement.Expression); depth > rule.max {
+			report(statement.Line, fmt.Sprintf("nested %d levels deep (the maximum is %d)", depth, rule.max))
+		}
+	}
+}
+
+// Returns the deepest nesting of blocks, functions and whens in the expression.
+func nestingDepth(expr Expression) int {
+	deepest := 0
+	for _, child := range children(expr) {
+		if depth := nestingDepth(child); depth > deepest {
+			deepest = depth
+		}
+	}
+
+	switch expr.(type) {
+	case BlockExpression, ExtendExpression, FunctionPrototypeExpression, ConditionalExpression:
+		return deepest + 1
+	}
+
+	return deepest
+}
+
+// Calls visit for the expression and everything inside it.
+func walk(expr Expression, visit func(Expression)) {
+	visit(expr)
+	for _, child := range children(expr) {
+		walk(child, visit)
+	}
+}
+
+// Returns the expressions directly inside the expression.
+// The names being defined by `=` are not included.
+func children(expr Expression) []Expression {
+	switch expr.(type) {
+	case BlockExpression:
+		return expr.(BlockExpression).Body
+
+	case FunctionPrototypeExpression:
+		function := expr.(FunctionPrototypeExpression)
+		exprs := []Expression{}
+		for _, arg := range function.Arguments {
+			if arg.Default != nil {
+				exprs = append(exprs, arg.Default)
+			}
+		}
+
+		return append(exprs, function.Body.Body...)
+
+	case ExtendExpression:
+		extend := expr.(ExtendExpression)
+		return append([]Expression{extend.Base}, extend.Body.Body...)
+
+	case ConditionalExpression:
+		conditional := expr.(ConditionalExpression)
+		exprs := []Expression{}
+		if conditional.Subject != nil {
+			exprs = append(exprs, conditional.Subject)
+		}
+
+		for _, branch := range conditional.Branches {
+			if _, ok := branch.Condition.(VariantPatternExpression); !ok {
+				exprs = append(exprs, branch.Condition)
+			}
+
+			exprs = append(exprs, branch.Body.Body...)
+		}
+
+		return exprs
+
+	case FunctionCallExpression:
+		call := expr.(FunctionCallExpression)
+		if call.Identifier.Name == "=" {
+			return call.Arguments[1:]
+		}
+
+		return call.Arguments
+
+	case NamedArgumentExpression:
+		return []Expression{expr.(NamedArgumentExpression).Value}
+	}
+
+	return []Expression{}
+}
+
+func isTrue(expr Expression) bool {
+	boolean, ok := expr.(BooleanExpression)
+	return ok && boolean.Value
+}
+
+// Returns the name of the literal's type, or "" if it is not a literal.
+func literalKind(expr Expression) string {
+	switch expr.(type) {
+	case NumberExpression:
+		return "Number"
+	case StringExpression:
+		return "String"
+	case BooleanExpression:
+		return "Boolean"
+	}
+
+	return ""
+}
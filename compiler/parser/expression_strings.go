@@ -3,6 +3,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 func (ne NumberExpression) String() string {
@@ -18,7 +19,7 @@ func (be BooleanExpression) String() string {
 }
 
 func (ie IdentifierExpression) String() string {
-	return fmt.Sprintf(ie.Name)
+	return ie.Name
 }
 
 func (be BlockExpression) String() string {
@@ -51,7 +52,37 @@ func (args arguments) String() string {
 	return str.String()
 }
 
+func (ae ArgumentExpression) String() string {
+	if ae.Rest {
+		return "..." + ae.Pattern.String()
+	}
+
+	if ae.Lazy {
+		return "lazy " + ae.Pattern.String()
+	}
+
+	pattern := ae.Pattern.String()
+	if ae.Type != nil {
+		pattern = fmt.Sprintf("%s: %s", pattern, ae.Type.String())
+	}
+
+	if ae.Default != nil {
+		return fmt.Sprintf("%s = %s", pattern, ae.Default.String())
+	}
+
+	return pattern
+}
+
 func (fpe FunctionPrototypeExpression) String() string {
+	if fpe.ReturnType != nil {
+		return fmt.Sprintf(
+			"%s -> %s %s",
+			fpe.Arguments.String(),
+			fpe.ReturnType.String(),
+			fpe.Body.String(),
+		)
+	}
+
 	return fmt.Sprintf(
 		"%s %s",
 		fpe.Arguments.String(),
@@ -83,9 +114,25 @@ func (fce FunctionCallExpression) String() string {
 	)
 }
 
+func (nae NamedArgumentExpression) String() string {
+	return fmt.Sprintf("%s: %s", nae.Name, nae.Value.String())
+}
+
+func (ee ExtendExpression) String() string {
+	return fmt.Sprintf(
+		"extend(%s) %s",
+		ee.Base.String(),
+		ee.Body.String(),
+	)
+}
+
 func (ce ConditionalExpression) String() string {
 	var str bytes.Buffer
-	str.WriteString("when {\n")
+	if ce.Subject != nil {
+		str.WriteString("when " + ce.Subject.String() + " {\n")
+	} else {
+		str.WriteString("when {\n")
+	}
 
 	for _, cond := range ce.Branches {
 		str.WriteString("  ")
@@ -104,3 +151,64 @@ func (cbe ConditionalBranchExpression) String() string {
 		cbe.Body.String(),
 	)
 }
+
+func (te TypeExpression) String() string {
+	if len(te.Parameters) == 0 {
+		return te.Name
+	}
+
+	return te.Name + params(te.Parameters).String()
+}
+
+func (fte FunctionTypeExpression) String() string {
+	return fmt.Sprintf("%s -> %s", params(fte.Arguments).String(), fte.Return.String())
+}
+
+func (tpe TypedPatternExpression) String() string {
+	return fmt.Sprintf("%s: %s", tpe.Pattern.String(), tpe.Type.String())
+}
+
+func (vpe VariantPatternExpression) String() string {
+	if vpe.Fields == nil {
+		return vpe.Name
+	}
+
+	fields := []string{}
+	for _, field := range vpe.Fields {
+		fields = append(fields, field.String())
+	}
+
+	return fmt.Sprintf("%s(%s)", vpe.Name, strings.Join(fields, ", "))
+}
+
+func (lpe ListPatternExpression) String() string {
+	var str bytes.Buffer
+	str.WriteString("List(")
+
+	lastIdx := len(lpe.Items) - 1
+	for idx, item := range lpe.Items {
+		str.WriteString(item.String())
+		if idx != lastIdx {
+			str.WriteString(", ")
+		}
+	}
+
+	str.WriteString(")")
+	return str.String()
+}
+
+func (bpe BlockPatternExpression) String() string {
+	var str bytes.Buffer
+	str.WriteString("{ ")
+
+	lastIdx := len(bpe.Names) - 1
+	for idx, name := range bpe.Names {
+		str.WriteString(name.String())
+		if idx != lastIdx {
+			str.WriteString(", ")
+		}
+	}
+
+	str.WriteString(" }")
+	return str.String()
+}
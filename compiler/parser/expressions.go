@@ -29,12 +29,23 @@ type BlockExpression struct {
 	Body []Expression
 }
 
-type arguments []IdentifierExpression
+// An argument in a function prototype.
+// The Pattern is an identifier or a destructuring pattern.
+type ArgumentExpression struct {
+	Pattern Expression
+	Type    Expression // nil if the argument has no type annotation.
+	Default Expression // nil if the argument is required.
+	Rest    bool       // true for `...rest` arguments.
+	Lazy    bool       // true for `lazy` arguments, which are evaluated when first used.
+}
+
+type arguments []ArgumentExpression
 
 // A function prototype (a literal).
 type FunctionPrototypeExpression struct {
-	Arguments arguments
-	Body      BlockExpression
+	Arguments  arguments
+	ReturnType Expression // nil if the function has no return type annotation.
+	Body       BlockExpression
 }
 
 type params []Expression
@@ -43,15 +54,69 @@ type params []Expression
 type FunctionCallExpression struct {
 	Identifier IdentifierExpression
 	Arguments  params
+	Line       int // The line of the function name or infix operator.
+}
+
+// A named argument in a function call, e.g. `b: 3` in `f(b: 3)`.
+type NamedArgumentExpression struct {
+	Name  string
+	Value Expression
+}
+
+// A block extending another, e.g. `extend(base) { ... }`.
+type ExtendExpression struct {
+	Base Expression
+	Body BlockExpression
 }
 
 // A conditional expression.
+// If it has a Subject, the branch conditions are patterns matched against it.
 type ConditionalExpression struct {
+	Subject  Expression
 	Branches []ConditionalBranchExpression
+	Line     int // The line of the `when`.
 }
 
 // A branch of a conditional expression.
 type ConditionalBranchExpression struct {
 	Condition Expression
 	Body      BlockExpression
+	Line      int // The line the condition starts on.
+}
+
+// A type annotation, e.g. `Number` or `List(String)`.
+// Type annotations are checked by `fn check`, and ignored when running.
+type TypeExpression struct {
+	Name       string
+	Parameters []Expression
+}
+
+// A function type annotation, e.g. `(Number, Number) -> Number`.
+type FunctionTypeExpression struct {
+	Arguments []Expression
+	Return    Expression
+}
+
+// A definition with a type annotation, e.g. `x: Number = 1`.
+type TypedPatternExpression struct {
+	Pattern Expression
+	Type    Expression
+}
+
+// A pattern matching a variant of a union, e.g. `Circle(r)`.
+// Fields is nil for a bare name, e.g. `Circle`, which matches any fields.
+type VariantPatternExpression struct {
+	Name   string
+	Fields []Expression
+}
+
+// A list destructuring pattern, e.g. `List(a, b) = pair`.
+// Items are identifiers or nested patterns.
+type ListPatternExpression struct {
+	Items []Expression
+}
+
+// A block destructuring pattern, e.g. `{ foo, bar } = block`.
+type BlockPatternExpression struct {
+	Names []IdentifierExpression
 }
@@ -7,34 +7,61 @@ import (
 
 // Converts a list of Tokens into a list of Expressions.
 func Parse(tokens tokenList) ([]Expression, error) {
+	statements, err := ParseStatements(tokens)
+
 	expressions := []Expression{}
+	for _, statement := range statements {
+		expressions = append(expressions, statement.Expression)
+	}
+
+	return expressions, err
+}
+
+// A top-level expression and the line it starts on.
+type Statement struct {
+	Expression Expression
+	Line       int
+}
+
+// Converts a list of Tokens into a list of Statements.
+func ParseStatements(tokens tokenList) ([]Statement, error) {
+	statements := []Statement{}
 
 	for tokens.Any() {
+		line := tokens.Next().Line
 		newExpression, remainingTokens, err := parsePrimary(tokens)
 
 		if newExpression != nil {
-			expressions = append(expressions, newExpression)
+			statements = append(statements, Statement{Expression: newExpression, Line: line})
 		} else if err != nil {
 			// Die on the first error.
-			return expressions, err
+			return statements, err
 		} else if len(remainingTokens) == len(tokens) {
-			return expressions, errors.New("Parser stalled!")
+			return statements, errors.New("Parser stalled!")
 		}
 
 		tokens = remainingTokens
 	}
 
-	return expressions, nil
+	return statements, nil
 }
 
 // Parse a top-level expression.
 // Primaries are of the form `value | import`
 func parsePrimary(tokens tokenList) (Expression, tokenList, error) {
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before expression")
+	}
+
 	switch tokens.Next().Type {
 	case "end_statement":
 		return nil, tokens[1:], nil
-	case "identifier", "number", "string", "boolean", "bracket_open", "when", "block_open":
+	case "identifier", "number", "string", "boolean", "bracket_open", "when", "extend", "block_open":
 		return parseValue(tokens)
+	case "infix_operator":
+		if isOperatorDefinition(tokens) {
+			return parseValue(tokens)
+		}
 	}
 
 	// TODO: import
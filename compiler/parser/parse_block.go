@@ -25,7 +25,10 @@ func parseBlock(tokens tokenList) (BlockExpression, tokenList, error) {
 			return BlockExpression{}, tokens, err
 		}
 
-		body = append(body, newExpression)
+		if newExpression != nil {
+			body = append(body, newExpression)
+		}
+
 		tokens = remainingTokens
 	}
 
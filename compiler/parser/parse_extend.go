@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Parses an extend expression of the form:
+// `extend ( value ) { primary* }`
+func parseExtend(tokens tokenList) (ExtendExpression, tokenList, error) {
+	if tokens.Next().Type != "extend" {
+		return ExtendExpression{}, tokens, errors.New(
+			fmt.Sprintf("Expected extend, found %s in extend expression", tokens.Next().Type),
+		)
+	}
+
+	tokens = tokens.Pop() // Eat extend
+
+	if !tokens.Any() {
+		return ExtendExpression{}, tokens, errors.New("End of file reached before extend base")
+	}
+
+	base, tokens, err := parseBrackets(tokens)
+	if err != nil {
+		return ExtendExpression{}, tokens, err
+	}
+
+	if !tokens.Any() {
+		return ExtendExpression{}, tokens, errors.New("End of file reached before extend block")
+	}
+
+	body, tokens, err := parseBlock(tokens)
+	if err != nil {
+		return ExtendExpression{}, tokens, err
+	}
+
+	return ExtendExpression{Base: base, Body: body}, tokens, nil
+}
@@ -15,6 +15,7 @@ func parseFunctionCall(tokens tokenList) (FunctionCallExpression, tokenList, err
 	}
 
 	id := IdentifierExpression{Name: tokens.Next().Value}
+	line := tokens.Next().Line
 	tokens = tokens.Pop() // Eat identifier
 
 	args, tokens, err := parseParams(tokens)
@@ -25,10 +26,11 @@ func parseFunctionCall(tokens tokenList) (FunctionCallExpression, tokenList, err
 	return FunctionCallExpression{
 		Identifier: id,
 		Arguments:  args,
+		Line:       line,
 	}, tokens, nil
 }
 
-// Parse a parameter list roughly of the form `( [value ,]* )`
+// Parse a parameter list roughly of the form `( [value ,]* [identifier: value ,]* )`
 func parseParams(tokens tokenList) ([]Expression, tokenList, error) {
 	if tokens.Next().Type != "bracket_open" {
 		return nil, tokens, errors.New(
@@ -44,8 +46,17 @@ func parseParams(tokens tokenList) ([]Expression, tokenList, error) {
 	)
 
 	args := []Expression{}
-	for tokens.Next().Type != "bracket_close" {
-		arg, tokens, err = parseValue(tokens)
+	named := false
+	for tokens.Any() && tokens.Next().Type != "bracket_close" {
+		if tokens.Length() > 1 && tokens.Next().Type == "identifier" && tokens.Peek(1).Type == "colon" {
+			arg, tokens, err = parseNamedArgument(tokens)
+			named = true
+		} else if named {
+			return nil, tokens, errors.New("Positional parameters cannot follow named parameters")
+		} else {
+			arg, tokens, err = parseValue(tokens)
+		}
+
 		if err != nil {
 			return args, tokens, err
 		}
@@ -70,7 +81,24 @@ func parseParams(tokens tokenList) ([]Expression, tokenList, error) {
 		)
 	}
 
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before parameter list closed.")
+	}
+
 	tokens = tokens.Pop() // Eat bracket_close
 
 	return args, tokens, nil
 }
+
+// Parse a named parameter of the form `identifier: value`
+func parseNamedArgument(tokens tokenList) (NamedArgumentExpression, tokenList, error) {
+	name := tokens.Next().Value
+	tokens = tokens.Pop().Pop() // Eat identifier and colon
+
+	value, tokens, err := parseValue(tokens)
+	if err != nil {
+		return NamedArgumentExpression{}, tokens, err
+	}
+
+	return NamedArgumentExpression{Name: name, Value: value}, tokens, nil
+}
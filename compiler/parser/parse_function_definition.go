@@ -6,12 +6,13 @@ import (
 )
 
 // Parse a function definition
-// A function definition has the form `(args) { primary* }`
+// A function definition has the form `(args) [-> type]? { primary* }`
 func parseFunctionDefinition(tokens tokenList) (FunctionPrototypeExpression, tokenList, error) {
 	var (
-		args []IdentifierExpression
-		body BlockExpression
-		err  error
+		args       []ArgumentExpression
+		returnType Expression
+		body       BlockExpression
+		err        error
 	)
 
 	args, tokens, err = parseArgs(tokens)
@@ -19,19 +20,28 @@ func parseFunctionDefinition(tokens tokenList) (FunctionPrototypeExpression, tok
 		return FunctionPrototypeExpression{}, tokens, err
 	}
 
+	if tokens.Any() && tokens.Next().Type == "arrow" {
+		returnType, tokens, err = parseType(tokens.Pop())
+		if err != nil {
+			return FunctionPrototypeExpression{}, tokens, err
+		}
+	}
+
 	body, tokens, err = parseBlock(tokens)
 	if err != nil {
 		return FunctionPrototypeExpression{}, tokens, err
 	}
 
 	return FunctionPrototypeExpression{
-		Arguments: args,
-		Body:      body,
+		Arguments:  args,
+		ReturnType: returnType,
+		Body:       body,
 	}, tokens, nil
 }
 
-// Parses an argument list of the rough form `( [identifier ,]+ )`
-func parseArgs(tokens tokenList) ([]IdentifierExpression, tokenList, error) {
+// Parses an argument list of the rough form
+// `( [pattern [= value]? ,]* [...identifier]? )`
+func parseArgs(tokens tokenList) ([]ArgumentExpression, tokenList, error) {
 	if tokens.Next().Type != "bracket_open" {
 		return nil, tokens, errors.New(
 			fmt.Sprintf("Expected bracket_open, found %s in argument list", tokens.Next().Type),
@@ -40,17 +50,37 @@ func parseArgs(tokens tokenList) ([]IdentifierExpression, tokenList, error) {
 
 	tokens = tokens.Pop() // Eat bracket_open
 
-	args := []IdentifierExpression{}
-	for tokens.Next().Type != "bracket_close" {
-		// Arguments are always identifiers.
-		if tokens.Next().Type != "identifier" {
+	var (
+		arg ArgumentExpression
+		err error
+	)
+
+	args := []ArgumentExpression{}
+	for tokens.Any() && tokens.Next().Type != "bracket_close" {
+		if len(args) > 0 && args[len(args)-1].Rest {
+			return nil, tokens, errors.New(
+				fmt.Sprintf("Argument %s must be the last in the argument list", args[len(args)-1].String()),
+			)
+		}
+
+		arg, tokens, err = parseArg(tokens)
+		if err != nil {
+			return nil, tokens, err
+		}
+
+		// Required arguments cannot follow arguments with defaults,
+		// as they could never be given positionally.
+		if len(args) > 0 && args[len(args)-1].Default != nil && arg.Default == nil && !arg.Rest {
 			return nil, tokens, errors.New(
-				fmt.Sprintf("Expected identifier, found %s in argument list", tokens.Next().Type),
+				fmt.Sprintf("Required argument %s follows an argument with a default", arg.String()),
 			)
 		}
 
-		args = append(args, IdentifierExpression{Name: tokens.Next().Value})
-		tokens = tokens.Pop()
+		args = append(args, arg)
+
+		if !tokens.Any() {
+			break
+		}
 
 		switch tokens.Next().Type {
 		case "comma":
@@ -66,6 +96,60 @@ func parseArgs(tokens tokenList) ([]IdentifierExpression, tokenList, error) {
 		)
 	}
 
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before argument list closed.")
+	}
+
 	tokens = tokens.Pop() // Eat bracket_close
 	return args, tokens, nil
 }
+
+// Parses a single argument of the form `pattern [: type]? [= value]?`,
+// `...identifier [: type]?` or `lazy identifier [: type]?`
+func parseArg(tokens tokenList) (ArgumentExpression, tokenList, error) {
+	// `lazy` is only a keyword when followed by the argument name.
+	if tokens.Length() > 1 && tokens.Next().Type == "identifier" && tokens.Next().Value == "lazy" &&
+		tokens.Peek(1).Type == "identifier" {
+		lazy := IdentifierExpression{Name: tokens.Peek(1).Value}
+
+		argType, tokens, err := parseAnnotation(tokens.Pop().Pop())
+		return ArgumentExpression{Pattern: lazy, Type: argType, Lazy: true}, tokens, err
+	}
+
+	if tokens.Next().Type == "ellipsis" {
+		tokens = tokens.Pop() // Eat ellipsis
+
+		if !tokens.Any() || tokens.Next().Type != "identifier" {
+			return ArgumentExpression{}, tokens, errors.New("Expected identifier after ... in argument list")
+		}
+
+		rest := IdentifierExpression{Name: tokens.Next().Value}
+
+		argType, tokens, err := parseAnnotation(tokens.Pop())
+		return ArgumentExpression{Pattern: rest, Type: argType, Rest: true}, tokens, err
+	}
+
+	// Arguments are identifiers or destructuring patterns.
+	pattern, tokens, err := parsePattern(tokens)
+	if err != nil {
+		return ArgumentExpression{}, tokens, err
+	}
+
+	argType, tokens, err := parseAnnotation(tokens)
+	if err != nil {
+		return ArgumentExpression{}, tokens, err
+	}
+
+	if !tokens.Any() || tokens.Next().Type != "infix_operator" || tokens.Next().Value != "=" {
+		return ArgumentExpression{Pattern: pattern, Type: argType}, tokens, nil
+	}
+
+	tokens = tokens.Pop() // Eat =
+
+	defaultValue, tokens, err := parseValue(tokens)
+	if err != nil {
+		return ArgumentExpression{}, tokens, err
+	}
+
+	return ArgumentExpression{Pattern: pattern, Type: argType, Default: defaultValue}, tokens, nil
+}
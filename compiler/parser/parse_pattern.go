@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Parse a pattern, as used in argument lists.
+// Patterns are of the form `identifier | List( [pattern ,]* ) | block_pattern`
+func parsePattern(tokens tokenList) (Expression, tokenList, error) {
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before pattern")
+	}
+
+	switch tokens.Next().Type {
+	case "identifier":
+		if tokens.Length() > 1 && tokens.Peek(1).Type == "bracket_open" {
+			call, tokens, err := parseFunctionCall(tokens)
+			if err != nil {
+				return nil, tokens, err
+			}
+
+			pattern, err := asPattern(call)
+			return pattern, tokens, err
+		}
+
+		id := IdentifierExpression{Name: tokens.Next().Value}
+		return id, tokens.Pop(), nil
+
+	case "block_open":
+		return parseBlockPattern(tokens)
+	}
+
+	return nil, tokens, errors.New(
+		fmt.Sprintf("Expected identifier, found %s in pattern", tokens.Next().Type),
+	)
+}
+
+// Parse a block pattern of the form `{ [identifier ,]* }`
+func parseBlockPattern(tokens tokenList) (BlockPatternExpression, tokenList, error) {
+	if tokens.Next().Type != "block_open" {
+		return BlockPatternExpression{}, tokens, errors.New(
+			fmt.Sprintf("Expected block_open, found %s in block pattern", tokens.Next().Type),
+		)
+	}
+
+	tokens = tokens.Pop() // Eat block_open
+
+	names := []IdentifierExpression{}
+	for tokens.Any() && tokens.Next().Type != "block_close" {
+		if tokens.Next().Type != "identifier" {
+			return BlockPatternExpression{}, tokens, errors.New(
+				fmt.Sprintf("Expected identifier, found %s in block pattern", tokens.Next().Type),
+			)
+		}
+
+		names = append(names, IdentifierExpression{Name: tokens.Next().Value})
+		tokens = tokens.Pop()
+
+		if !tokens.Any() {
+			break
+		}
+
+		switch tokens.Next().Type {
+		case "comma":
+			tokens = tokens.Pop() // Remove comma
+			continue
+		case "block_close":
+			continue // The loop will end
+		}
+
+		// If we get here, we didn't get anything we expected...
+		return BlockPatternExpression{}, tokens, errors.New(
+			fmt.Sprintf("Unexpected %s in block pattern", tokens.Next().Type),
+		)
+	}
+
+	if !tokens.Any() {
+		return BlockPatternExpression{}, tokens, errors.New("End of file reached before closing block pattern")
+	}
+
+	tokens = tokens.Pop() // Eat block_close
+
+	return BlockPatternExpression{Names: names}, tokens, nil
+}
+
+// Returns true if the tokens start with a block pattern
+// that is being assigned to, e.g. `{ foo, bar } = block`
+func isBlockPattern(tokens tokenList) bool {
+	if tokens.Next().Type != "block_open" {
+		return false
+	}
+
+	for idx := 1; idx < tokens.Length(); idx++ {
+		switch tokens.Peek(idx).Type {
+		case "identifier", "comma":
+			continue
+		case "block_close":
+			after := tokens.AfterMatching("block_open", "block_close")
+			return after != nil && after.Type == "infix_operator" && after.Value == "="
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// Converts the left-hand side of a definition into a pattern.
+// Returns an error if the expression cannot be assigned to.
+func asPattern(expr Expression) (Expression, error) {
+	switch expr.(type) {
+	case IdentifierExpression, ListPatternExpression, BlockPatternExpression, TypedPatternExpression:
+		return expr, nil
+
+	case FunctionCallExpression:
+		call := expr.(FunctionCallExpression)
+		if call.Identifier.Name != "List" {
+			break
+		}
+
+		items := []Expression{}
+		for _, arg := range call.Arguments {
+			item, err := asPattern(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return ListPatternExpression{Items: items}, nil
+
+	case BlockExpression:
+		// Nested blocks such as `List(a, { b }) = x` are parsed as block literals.
+		names := []IdentifierExpression{}
+		for _, item := range expr.(BlockExpression).Body {
+			name, ok := item.(IdentifierExpression)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Cannot assign to %s", expr.String()))
+			}
+
+			names = append(names, name)
+		}
+
+		return BlockPatternExpression{Names: names}, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("Cannot assign to %s", expr.String()))
+}
+
+// Parse a definition with a type annotation, e.g. `x: Number = 1`.
+// Only the pattern and the annotation are parsed; the `=` must follow.
+func parseTypedPattern(tokens tokenList) (TypedPatternExpression, tokenList, error) {
+	id := IdentifierExpression{Name: tokens.Next().Value}
+
+	typeExpr, tokens, err := parseAnnotation(tokens.Pop())
+	if err != nil {
+		return TypedPatternExpression{}, tokens, err
+	}
+
+	if !tokens.Any() || tokens.Next().Type != "infix_operator" || tokens.Next().Value != "=" {
+		return TypedPatternExpression{}, tokens, errors.New(
+			fmt.Sprintf("Expected = after type annotation %s: %s", id.Name, typeExpr.String()),
+		)
+	}
+
+	return TypedPatternExpression{Pattern: id, Type: typeExpr}, tokens, nil
+}
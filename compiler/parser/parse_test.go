@@ -28,6 +28,38 @@ func TestParser(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 
+	Convey("Incomplete expressions return an error", t, func() {
+		for _, code := range []string{"x = ", "x.", "1 +", "(", "f(1,"} {
+			_, err := Parse(tokensFor(code))
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Statements keep the line they start on", t, func() {
+		statements, err := ParseStatements(tokensFor("foo\n\nbar = {\n  1\n}; baz"))
+
+		So(err, ShouldBeNil)
+		So(len(statements), ShouldEqual, 3)
+		So(statements[0].Line, ShouldEqual, 1)
+		So(statements[1].Line, ShouldEqual, 3)
+		So(statements[2].Line, ShouldEqual, 5)
+	})
+
+	Convey("Calls and whens keep the line they start on", t, func() {
+		exprs, err := Parse(tokensFor("f = () {\n  x = g(1)\n  when {\n    x { 1 }\n    true { 2 }\n  }\n}"))
+		So(err, ShouldBeNil)
+
+		body := exprs[0].(FunctionCallExpression).Arguments[1].(FunctionPrototypeExpression).Body.Body
+		definition := body[0].(FunctionCallExpression)
+		conditional := body[1].(ConditionalExpression)
+
+		So(exprs[0].(FunctionCallExpression).Line, ShouldEqual, 1)
+		So(definition.Line, ShouldEqual, 2)
+		So(definition.Arguments[1].(FunctionCallExpression).Line, ShouldEqual, 2)
+		So(conditional.Line, ShouldEqual, 3)
+		So(conditional.Branches[1].Line, ShouldEqual, 5)
+	})
+
 	Convey("Parsing value", t, func() {
 
 		Convey("identifiers become Identifier Expressions", func() {
@@ -44,6 +76,7 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionCallExpression{
+					Line:       1,
 					Identifier: IdentifierExpression{Name: "foo"},
 					Arguments: []Expression{
 						IdentifierExpression{Name: "bar"},
@@ -94,8 +127,8 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionPrototypeExpression{
-					Arguments: []IdentifierExpression{
-						IdentifierExpression{Name: "a"},
+					Arguments: []ArgumentExpression{
+						ArgumentExpression{Pattern: IdentifierExpression{Name: "a"}},
 					},
 					Body: BlockExpression{
 						Body: []Expression{
@@ -130,8 +163,10 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				ConditionalExpression{
+					Line: 1,
 					Branches: []ConditionalBranchExpression{
 						ConditionalBranchExpression{
+							Line:      1,
 							Condition: BooleanExpression{Value: true},
 							Body: BlockExpression{
 								Body: []Expression{
@@ -145,6 +180,169 @@ func TestParser(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 
+		Convey("extend statements become Extend Expressions", func() {
+			exprs, err := Parse(tokensFor("extend(base) { foo = 1 }"))
+
+			So(exprs, ShouldResemble, []Expression{
+				ExtendExpression{
+					Base: IdentifierExpression{Name: "base"},
+					Body: BlockExpression{
+						Body: []Expression{
+							FunctionCallExpression{
+								Line:       1,
+								Identifier: IdentifierExpression{Name: "="},
+								Arguments: []Expression{
+									IdentifierExpression{Name: "foo"},
+									NumberExpression{Value: "1"},
+								},
+							},
+						},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+	})
+
+	Convey("Type annotations", t, func() {
+
+		Convey("can be given for arguments", func() {
+			exprs, err := Parse(tokensFor("(a: Number, b: List(String) = x) { a }"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionPrototypeExpression{
+					Arguments: []ArgumentExpression{
+						ArgumentExpression{
+							Pattern: IdentifierExpression{Name: "a"},
+							Type:    TypeExpression{Name: "Number"},
+						},
+						ArgumentExpression{
+							Pattern: IdentifierExpression{Name: "b"},
+							Type: TypeExpression{
+								Name:       "List",
+								Parameters: []Expression{TypeExpression{Name: "String"}},
+							},
+							Default: IdentifierExpression{Name: "x"},
+						},
+					},
+					Body: BlockExpression{
+						Body: []Expression{IdentifierExpression{Name: "a"}},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("can be given for return values", func() {
+			exprs, err := Parse(tokensFor("(f: (Number) -> Number) -> Number { f(1) }"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionPrototypeExpression{
+					Arguments: []ArgumentExpression{
+						ArgumentExpression{
+							Pattern: IdentifierExpression{Name: "f"},
+							Type: FunctionTypeExpression{
+								Arguments: []Expression{TypeExpression{Name: "Number"}},
+								Return:    TypeExpression{Name: "Number"},
+							},
+						},
+					},
+					ReturnType: TypeExpression{Name: "Number"},
+					Body: BlockExpression{
+						Body: []Expression{
+							FunctionCallExpression{
+								Line:       1,
+								Identifier: IdentifierExpression{Name: "f"},
+								Arguments:  []Expression{NumberExpression{Value: "1"}},
+							},
+						},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("can be given for definitions", func() {
+			exprs, err := Parse(tokensFor("x: Number = 1"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "="},
+					Arguments: []Expression{
+						TypedPatternExpression{
+							Pattern: IdentifierExpression{Name: "x"},
+							Type:    TypeExpression{Name: "Number"},
+						},
+						NumberExpression{Value: "1"},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("fail on definitions without a value", func() {
+			_, err := Parse(tokensFor("x: Number"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("fail if a function type has no return type", func() {
+			_, err := Parse(tokensFor("(f: (Number)) { f }"))
+			So(err, ShouldNotBeNil)
+		})
+
+	})
+
+	Convey("Operator definitions", t, func() {
+
+		Convey("become definitions of the operator name", func() {
+			exprs, err := Parse(tokensFor("+ = other"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "="},
+					Arguments: []Expression{
+						IdentifierExpression{Name: "+"},
+						IdentifierExpression{Name: "other"},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("start a new expression", func() {
+			exprs, err := Parse(tokensFor("a = b\n+ = c"))
+
+			So(len(exprs), ShouldEqual, 2)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("fail for operators that cannot be defined", func() {
+			_, err := Parse(tokensFor("and = other"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("fail if the operator is not being defined", func() {
+			_, err := Parse(tokensFor("+ 1"))
+			So(err, ShouldNotBeNil)
+		})
+
+	})
+
+	Convey("Extend", t, func() {
+
+		Convey("fails if the base is not bracketed", func() {
+			_, err := Parse(tokensFor("extend base { foo }"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("fails if a block is not given after the base", func() {
+			_, err := Parse(tokensFor("extend(base)"))
+			So(err, ShouldNotBeNil)
+		})
+
 	})
 
 	Convey("Infix Operators", t, func() {
@@ -154,6 +352,7 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionCallExpression{
+					Line:       1,
 					Identifier: IdentifierExpression{Name: "+"},
 					Arguments: []Expression{
 						IdentifierExpression{Name: "a"},
@@ -169,10 +368,12 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionCallExpression{
+					Line:       1,
 					Identifier: IdentifierExpression{Name: "*"},
 					Arguments: []Expression{
 						IdentifierExpression{Name: "a"},
 						FunctionCallExpression{
+							Line:       1,
 							Identifier: IdentifierExpression{Name: "*"},
 							Arguments: []Expression{
 								IdentifierExpression{Name: "b"},
@@ -190,10 +391,12 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionCallExpression{
+					Line:       1,
 					Identifier: IdentifierExpression{Name: "+"},
 					Arguments: []Expression{
 						IdentifierExpression{Name: "a"},
 						FunctionCallExpression{
+							Line:       1,
 							Identifier: IdentifierExpression{Name: "*"},
 							Arguments: []Expression{
 								IdentifierExpression{Name: "b"},
@@ -208,6 +411,79 @@ func TestParser(t *testing.T) {
 
 	})
 
+	Convey("Pipelines", t, func() {
+
+		Convey("are executed left-to-right", func() {
+			exprs, err := Parse(tokensFor("a |> f |> g"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "|>"},
+					Arguments: []Expression{
+						FunctionCallExpression{
+							Line:       1,
+							Identifier: IdentifierExpression{Name: "|>"},
+							Arguments: []Expression{
+								IdentifierExpression{Name: "a"},
+								IdentifierExpression{Name: "f"},
+							},
+						},
+						IdentifierExpression{Name: "g"},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("bind more loosely than other operators", func() {
+			exprs, err := Parse(tokensFor("a + b |> f"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "|>"},
+					Arguments: []Expression{
+						FunctionCallExpression{
+							Line:       1,
+							Identifier: IdentifierExpression{Name: "+"},
+							Arguments: []Expression{
+								IdentifierExpression{Name: "a"},
+								IdentifierExpression{Name: "b"},
+							},
+						},
+						IdentifierExpression{Name: "f"},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("are defined whole", func() {
+			exprs, err := Parse(tokensFor("x = a |> f"))
+
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "="},
+					Arguments: []Expression{
+						IdentifierExpression{Name: "x"},
+						FunctionCallExpression{
+							Line:       1,
+							Identifier: IdentifierExpression{Name: "|>"},
+							Arguments: []Expression{
+								IdentifierExpression{Name: "a"},
+								IdentifierExpression{Name: "f"},
+							},
+						},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+	})
+
 	Convey("Blocks", t, func() {
 		Convey("fail on non-primary statements", func() {
 			_, err := Parse(tokensFor("{=}"))
@@ -218,6 +494,20 @@ func TestParser(t *testing.T) {
 			_, err := Parse(tokensFor("{foo"))
 			So(err, ShouldNotBeNil)
 		})
+
+		Convey("can be delimited by end_statement tokens", func() {
+			exprs, err := Parse(tokensFor("{ foo; bar }"))
+
+			So(err, ShouldBeNil)
+			So(exprs, ShouldResemble, []Expression{
+				BlockExpression{
+					Body: []Expression{
+						IdentifierExpression{Name: "foo"},
+						IdentifierExpression{Name: "bar"},
+					},
+				},
+			})
+		})
 	})
 
 	Convey("Brackets", t, func() {
@@ -236,10 +526,12 @@ func TestParser(t *testing.T) {
 
 			So(exprs, ShouldResemble, []Expression{
 				FunctionCallExpression{
+					Line:       1,
 					Identifier: IdentifierExpression{Name: "*"},
 					Arguments: []Expression{
 						IdentifierExpression{Name: "a"},
 						FunctionCallExpression{
+							Line:       1,
 							Identifier: IdentifierExpression{Name: "+"},
 							Arguments: []Expression{
 								IdentifierExpression{Name: "b"},
@@ -274,6 +566,27 @@ func TestParser(t *testing.T) {
 			_, err := Parse(tokensFor("foo(a, b)"))
 			So(err, ShouldBeNil)
 		})
+
+		Convey("permit named parameters", func() {
+			exprs, err := Parse(tokensFor("foo(a, b: 3)"))
+
+			So(err, ShouldBeNil)
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "foo"},
+					Arguments: []Expression{
+						IdentifierExpression{Name: "a"},
+						NamedArgumentExpression{Name: "b", Value: NumberExpression{Value: "3"}},
+					},
+				},
+			})
+		})
+
+		Convey("fail on positional parameters after named parameters", func() {
+			_, err := Parse(tokensFor("foo(b: 3, a)"))
+			So(err, ShouldNotBeNil)
+		})
 	})
 
 	Convey("Function Definitions", t, func() {
@@ -296,6 +609,139 @@ func TestParser(t *testing.T) {
 			_, err := Parse(tokensFor("foo(a, b) { true }"))
 			So(err, ShouldBeNil)
 		})
+
+		Convey("permit default values", func() {
+			exprs, err := Parse(tokensFor("(a, b = 2) { a }"))
+
+			So(err, ShouldBeNil)
+			So(exprs[0].(FunctionPrototypeExpression).Arguments, ShouldResemble, arguments{
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "a"}},
+				ArgumentExpression{
+					Pattern: IdentifierExpression{Name: "b"},
+					Default: NumberExpression{Value: "2"},
+				},
+			})
+		})
+
+		Convey("fail if a required argument follows a default", func() {
+			_, err := Parse(tokensFor("(a = 1, b) { a }"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("permit a rest argument", func() {
+			exprs, err := Parse(tokensFor("(first, ...rest) { rest }"))
+
+			So(err, ShouldBeNil)
+			So(exprs[0].(FunctionPrototypeExpression).Arguments, ShouldResemble, arguments{
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "first"}},
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "rest"}, Rest: true},
+			})
+		})
+
+		Convey("permit lazy arguments", func() {
+			exprs, err := Parse(tokensFor("(cond, lazy a) { a }"))
+
+			So(err, ShouldBeNil)
+			So(exprs[0].(FunctionPrototypeExpression).Arguments, ShouldResemble, arguments{
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "cond"}},
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "a"}, Lazy: true},
+			})
+		})
+
+		Convey("permit arguments named lazy", func() {
+			exprs, err := Parse(tokensFor("(lazy) { lazy }"))
+
+			So(err, ShouldBeNil)
+			So(exprs[0].(FunctionPrototypeExpression).Arguments, ShouldResemble, arguments{
+				ArgumentExpression{Pattern: IdentifierExpression{Name: "lazy"}},
+			})
+		})
+
+		Convey("fail if the rest argument is not last", func() {
+			_, err := Parse(tokensFor("(...rest, last) { rest }"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("permit destructuring patterns", func() {
+			exprs, err := Parse(tokensFor("(List(a, b), { c, d }) { a }"))
+
+			So(err, ShouldBeNil)
+			So(exprs, ShouldResemble, []Expression{
+				FunctionPrototypeExpression{
+					Arguments: []ArgumentExpression{
+						ArgumentExpression{Pattern: ListPatternExpression{
+							Items: []Expression{
+								IdentifierExpression{Name: "a"},
+								IdentifierExpression{Name: "b"},
+							},
+						}},
+						ArgumentExpression{Pattern: BlockPatternExpression{
+							Names: []IdentifierExpression{
+								IdentifierExpression{Name: "c"},
+								IdentifierExpression{Name: "d"},
+							},
+						}},
+					},
+					Body: BlockExpression{
+						Body: []Expression{
+							IdentifierExpression{Name: "a"},
+						},
+					},
+				},
+			})
+		})
+	})
+
+	Convey("Definitions", t, func() {
+		Convey("can destructure lists", func() {
+			exprs, err := Parse(tokensFor("List(a, b) = pair"))
+
+			So(err, ShouldBeNil)
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "="},
+					Arguments: []Expression{
+						ListPatternExpression{
+							Items: []Expression{
+								IdentifierExpression{Name: "a"},
+								IdentifierExpression{Name: "b"},
+							},
+						},
+						IdentifierExpression{Name: "pair"},
+					},
+				},
+			})
+		})
+
+		Convey("can destructure blocks", func() {
+			exprs, err := Parse(tokensFor("{ foo, bar } = block"))
+
+			So(err, ShouldBeNil)
+			So(exprs, ShouldResemble, []Expression{
+				FunctionCallExpression{
+					Line:       1,
+					Identifier: IdentifierExpression{Name: "="},
+					Arguments: []Expression{
+						BlockPatternExpression{
+							Names: []IdentifierExpression{
+								IdentifierExpression{Name: "foo"},
+								IdentifierExpression{Name: "bar"},
+							},
+						},
+						IdentifierExpression{Name: "block"},
+					},
+				},
+			})
+		})
+
+		Convey("fail if the left-hand side cannot be assigned to", func() {
+			_, err := Parse(tokensFor("1 = 2"))
+			So(err, ShouldNotBeNil)
+
+			_, err = Parse(tokensFor("foo(a) = 2"))
+			So(err, ShouldNotBeNil)
+		})
 	})
 
 	Convey("Conditionals", t, func() {
@@ -315,12 +761,57 @@ func TestParser(t *testing.T) {
 			So(err, ShouldNotBeNil)
 		})
 
+		Convey("with a subject have variant patterns as conditions", func() {
+			exprs, err := Parse(tokensFor("when shape { Circle(r) { r } Empty { 0 } true { 1 } }"))
+
+			So(exprs, ShouldResemble, []Expression{
+				ConditionalExpression{
+					Line:    1,
+					Subject: IdentifierExpression{Name: "shape"},
+					Branches: []ConditionalBranchExpression{
+						ConditionalBranchExpression{
+							Line: 1,
+							Condition: VariantPatternExpression{
+								Name:   "Circle",
+								Fields: []Expression{IdentifierExpression{Name: "r"}},
+							},
+							Body: BlockExpression{
+								Body: []Expression{IdentifierExpression{Name: "r"}},
+							},
+						},
+						ConditionalBranchExpression{
+							Line:      1,
+							Condition: VariantPatternExpression{Name: "Empty"},
+							Body: BlockExpression{
+								Body: []Expression{NumberExpression{Value: "0"}},
+							},
+						},
+						ConditionalBranchExpression{
+							Line:      1,
+							Condition: BooleanExpression{Value: true},
+							Body: BlockExpression{
+								Body: []Expression{NumberExpression{Value: "1"}},
+							},
+						},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("with a subject fail if a condition is not a pattern", func() {
+			_, err := Parse(tokensFor("when shape { 1 { r } }"))
+			So(err, ShouldNotBeNil)
+		})
+
 		Convey("can have multiple branches", func() {
 			exprs, err := Parse(tokensFor("when { false { foo } true { bar } }"))
 			So(exprs, ShouldResemble, []Expression{
 				ConditionalExpression{
+					Line: 1,
 					Branches: []ConditionalBranchExpression{
 						ConditionalBranchExpression{
+							Line:      1,
 							Condition: BooleanExpression{Value: false},
 							Body: BlockExpression{
 								Body: []Expression{
@@ -329,6 +820,7 @@ func TestParser(t *testing.T) {
 							},
 						},
 						ConditionalBranchExpression{
+							Line:      1,
 							Condition: BooleanExpression{Value: true},
 							Body: BlockExpression{
 								Body: []Expression{
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Parse a type annotation.
+// Types are of the form `identifier [( [type ,]* )]? | ( [type ,]* ) -> type`
+func parseType(tokens tokenList) (Expression, tokenList, error) {
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before type")
+	}
+
+	switch tokens.Next().Type {
+	case "identifier":
+		name := tokens.Next().Value
+		tokens = tokens.Pop()
+
+		if !tokens.Any() || tokens.Next().Type != "bracket_open" {
+			return TypeExpression{Name: name}, tokens, nil
+		}
+
+		parameters, tokens, err := parseTypeList(tokens)
+		if err != nil {
+			return nil, tokens, err
+		}
+
+		return TypeExpression{Name: name, Parameters: parameters}, tokens, nil
+
+	case "bracket_open":
+		arguments, tokens, err := parseTypeList(tokens)
+		if err != nil {
+			return nil, tokens, err
+		}
+
+		if !tokens.Any() || tokens.Next().Type != "arrow" {
+			return nil, tokens, errors.New("Expected -> after function type arguments")
+		}
+
+		returnType, tokens, err := parseType(tokens.Pop())
+		if err != nil {
+			return nil, tokens, err
+		}
+
+		return FunctionTypeExpression{Arguments: arguments, Return: returnType}, tokens, nil
+	}
+
+	return nil, tokens, errors.New(
+		fmt.Sprintf("Expected type, found %s", tokens.Next().Type),
+	)
+}
+
+// Parse a bracketed list of types of the form `( [type ,]* )`
+func parseTypeList(tokens tokenList) ([]Expression, tokenList, error) {
+	tokens = tokens.Pop() // Eat bracket_open
+
+	types := []Expression{}
+	for tokens.Any() && tokens.Next().Type != "bracket_close" {
+		typeExpr, remaining, err := parseType(tokens)
+		if err != nil {
+			return nil, remaining, err
+		}
+
+		types = append(types, typeExpr)
+		tokens = remaining
+
+		if tokens.Any() && tokens.Next().Type == "comma" {
+			tokens = tokens.Pop() // Remove comma
+		} else if tokens.Any() && tokens.Next().Type != "bracket_close" {
+			return nil, tokens, errors.New(
+				fmt.Sprintf("Unexpected %s in type list", tokens.Next().Type),
+			)
+		}
+	}
+
+	if !tokens.Any() {
+		return nil, tokens, errors.New("End of file reached before type list closed")
+	}
+
+	return types, tokens.Pop(), nil
+}
+
+// Parse an optional type annotation of the form `: type`
+// Returns a nil type if there is no annotation.
+func parseAnnotation(tokens tokenList) (Expression, tokenList, error) {
+	if !tokens.Any() || tokens.Next().Type != "colon" {
+		return nil, tokens, nil
+	}
+
+	return parseType(tokens.Pop())
+}
@@ -8,8 +8,13 @@ import (
 // Parse a value.
 // Values are of the form
 // `identifier | function_call | number | string | boolean |
-//  function_definition | brackets | block | when`
+//  function_definition | brackets | block | when | extend`
 func parseValue(tokens tokenList) (Expression, tokenList, error) {
+	return parseValueAbove(tokens, -1)
+}
+
+// Parse a value, stopping at any infix operator with a lower precedence.
+func parseValueAbove(tokens tokenList, precedence float32) (Expression, tokenList, error) {
 	// We need to parse the left and right hand side for the value.
 	var (
 		lhs Expression
@@ -23,11 +28,24 @@ func parseValue(tokens tokenList) (Expression, tokenList, error) {
 		if tokens.Length() > 1 && tokens.Peek(1).Type == "bracket_open" {
 			// TODO: Error checking!
 			lhs, tokens, err = parseFunctionCall(tokens)
+		} else if tokens.Length() > 1 && tokens.Peek(1).Type == "colon" {
+			lhs, tokens, err = parseTypedPattern(tokens)
 		} else {
 			lhs = IdentifierExpression{Name: tokens.Next().Value}
 			tokens = tokens.Pop()
 		}
 
+	// Blocks can define operators, e.g. `+ = (other) { ... }`
+	case "infix_operator":
+		if !isOperatorDefinition(tokens) {
+			return nil, tokens, errors.New(
+				fmt.Sprintf("Unexpected %s at start of value", tokens.Next().Value),
+			)
+		}
+
+		lhs = IdentifierExpression{Name: tokens.Next().Value}
+		tokens = tokens.Pop()
+
 	// Basic literals
 	case "number":
 		lhs = NumberExpression{Value: tokens.Next().Value}
@@ -40,21 +58,29 @@ func parseValue(tokens tokenList) (Expression, tokenList, error) {
 		tokens = tokens.Pop()
 
 	case "bracket_open":
-		// Find the token immediately after the closing bracket.
-		tokenAfterClosingBracket := tokens.AfterNext("bracket_close")
+		// Find the token immediately after the matching closing bracket.
+		tokenAfterClosingBracket := tokens.AfterMatching("bracket_open", "bracket_close")
 
 		// TODO: Error checking!
-		if tokenAfterClosingBracket != nil && tokenAfterClosingBracket.Type == "block_open" {
+		if tokenAfterClosingBracket != nil &&
+			(tokenAfterClosingBracket.Type == "block_open" || tokenAfterClosingBracket.Type == "arrow") {
 			lhs, tokens, err = parseFunctionDefinition(tokens)
 		} else {
 			lhs, tokens, err = parseBrackets(tokens)
 		}
 
 	case "block_open":
-		lhs, tokens, err = parseBlock(tokens)
+		if isBlockPattern(tokens) {
+			lhs, tokens, err = parseBlockPattern(tokens)
+		} else {
+			lhs, tokens, err = parseBlock(tokens)
+		}
 
 	case "when":
 		lhs, tokens, err = parseWhen(tokens)
+
+	case "extend":
+		lhs, tokens, err = parseExtend(tokens)
 	}
 
 	if err != nil {
@@ -68,7 +94,7 @@ func parseValue(tokens tokenList) (Expression, tokenList, error) {
 		)
 	}
 
-	return parseInfixRhs(tokens, -1, lhs)
+	return parseInfixRhs(tokens, precedence, lhs)
 }
 
 // Parse the Right-Hand side of an expression, respecting precedence.
@@ -79,23 +105,48 @@ func parseInfixRhs(tokens tokenList, precedence float32, lhs Expression) (Expres
 	)
 
 	for tokens.Any() {
+		// An operator definition starts a new expression.
+		if isOperatorDefinition(tokens) {
+			break
+		}
+
 		beforeParsePrecedence := precedenceOf(tokens.Next())
 
 		if beforeParsePrecedence < precedence {
 			break
 		}
 
-		operation := tokens.Next().Value
+		operation, line := tokens.Next().Value, tokens.Next().Line
 		tokens = tokens.Pop() // Eat infix_operator
 
-		rhs, tokens, err = parseValue(tokens)
+		// The left-hand side of a definition must be something we can assign to.
+		if operation == "=" {
+			lhs, err = asPattern(lhs)
+			if err != nil {
+				return nil, tokens, err
+			}
+		}
+
+		if !tokens.Any() {
+			return nil, tokens, errors.New(fmt.Sprintf("End of file reached after %s", operation))
+		}
+
+		// Pipelines bind loosest, so `a + b |> f` is `(a + b) |> f`
+		// and `a |> f |> g` is `(a |> f) |> g`.
+		// A definition takes everything after it, as in `x = a |> f`.
+		rhsPrecedence := float32(-1)
+		if operation != "=" {
+			rhsPrecedence = operatorPrecedence("|>") + 1
+		}
+
+		rhs, tokens, err = parseValueAbove(tokens, rhsPrecedence)
 		if err != nil {
 			return rhs, tokens, err
 		}
 
 		// If, after parsing, the current token has a higher precedence,
 		// we need to use everything we have so far at the LHS of the higher expression.
-		if tokens.Any() && beforeParsePrecedence < precedenceOf(tokens.Next()) {
+		if tokens.Any() && !isOperatorDefinition(tokens) && beforeParsePrecedence < precedenceOf(tokens.Next()) {
 			rhs, tokens, err = parseInfixRhs(tokens, precedence, rhs)
 			if err != nil {
 				return rhs, tokens, err
@@ -108,8 +159,25 @@ func parseInfixRhs(tokens tokenList, precedence float32, lhs Expression) (Expres
 				lhs,
 				rhs,
 			},
+			Line: line,
 		}
 	}
 
 	return lhs, tokens, nil
 }
+
+// Returns true if the tokens start with the definition of an operator,
+// e.g. `+ = (other) { ... }`
+func isOperatorDefinition(tokens tokenList) bool {
+	if tokens.Length() < 2 || tokens.Peek(1).Type != "infix_operator" || tokens.Peek(1).Value != "=" {
+		return false
+	}
+
+	for _, operator := range OverloadableOperators {
+		if tokens.Next().Value == operator {
+			return true
+		}
+	}
+
+	return false
+}
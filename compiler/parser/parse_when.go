@@ -6,7 +6,8 @@ import (
 )
 
 // Parses a when statement of the form:
-// `when { [value { primary+ }]* }`
+// `when [value] { [condition { primary+ }]* }`
+// If a subject value is given, each condition is a variant pattern or `true`.
 func parseWhen(tokens tokenList) (ConditionalExpression, tokenList, error) {
 	if tokens.Next().Type != "when" {
 		return ConditionalExpression{}, tokens, errors.New(
@@ -14,8 +15,28 @@ func parseWhen(tokens tokenList) (ConditionalExpression, tokenList, error) {
 		)
 	}
 
+	line := tokens.Next().Line
 	tokens = tokens.Pop() // Eat when
 
+	if !tokens.Any() {
+		return ConditionalExpression{}, tokens, errors.New("End of file reached before when block")
+	}
+
+	var (
+		subject Expression
+		err     error
+	)
+	if tokens.Next().Type != "block_open" {
+		subject, tokens, err = parseValue(tokens)
+		if err != nil {
+			return ConditionalExpression{}, tokens, err
+		}
+	}
+
+	if !tokens.Any() {
+		return ConditionalExpression{}, tokens, errors.New("End of file reached before when block")
+	}
+
 	if tokens.Next().Type != "block_open" {
 		return ConditionalExpression{}, tokens, errors.New(
 			fmt.Sprintf("Expected block_open, found %s in when expression", tokens.Next().Type),
@@ -27,16 +48,23 @@ func parseWhen(tokens tokenList) (ConditionalExpression, tokenList, error) {
 	var (
 		condition Expression
 		block     BlockExpression
-		err       error
 	)
 	branches := []ConditionalBranchExpression{}
 
-	for tokens.Next().Type != "block_close" {
+	for tokens.Any() && tokens.Next().Type != "block_close" {
+		branchLine := tokens.Next().Line
 		condition, tokens, err = parseValue(tokens)
 		if err != nil {
 			return ConditionalExpression{}, tokens, err
 		}
 
+		if subject != nil {
+			condition, err = asVariantPattern(condition)
+			if err != nil {
+				return ConditionalExpression{}, tokens, err
+			}
+		}
+
 		block, tokens, err = parseBlock(tokens)
 		if err != nil {
 			return ConditionalExpression{}, tokens, err
@@ -45,10 +73,45 @@ func parseWhen(tokens tokenList) (ConditionalExpression, tokenList, error) {
 		branches = append(branches, ConditionalBranchExpression{
 			Condition: condition,
 			Body:      block,
+			Line:      branchLine,
 		})
 	}
 
+	if !tokens.Any() {
+		return ConditionalExpression{}, tokens, errors.New("End of file reached before closing when block")
+	}
+
 	tokens = tokens.Pop() // Eat block_close
 
-	return ConditionalExpression{Branches: branches}, tokens, nil
+	return ConditionalExpression{Subject: subject, Branches: branches, Line: line}, tokens, nil
+}
+
+// Converts a branch condition of a when with a subject into a pattern.
+// `true` is left as it is, as it matches anything.
+func asVariantPattern(condition Expression) (Expression, error) {
+	switch condition.(type) {
+	case BooleanExpression:
+		if condition.(BooleanExpression).Value {
+			return condition, nil
+		}
+
+	case IdentifierExpression:
+		return VariantPatternExpression{Name: condition.(IdentifierExpression).Name}, nil
+
+	case FunctionCallExpression:
+		call := condition.(FunctionCallExpression)
+		fields := []Expression{}
+		for _, arg := range call.Arguments {
+			field, err := asPattern(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, field)
+		}
+
+		return VariantPatternExpression{Name: call.Identifier.Name, Fields: fields}, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("Expected a variant pattern, found %s", condition.String()))
 }
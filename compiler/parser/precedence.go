@@ -5,12 +5,36 @@ import (
 )
 
 // The precedence of infix operations.
-var InfixPrecedence = []string{".", "=", "eq", "and", "or", "*", "/", "+", "-"}
+var InfixPrecedence = []string{"|>", ".", "with", "=", "eq", "lessThan", "moreThan", "and", "or", "*", "/", "+", "-"}
+
+// The infix operators that blocks can define.
+var OverloadableOperators = []string{"+", "-", "*", "/", "eq", "lessThan", "moreThan"}
+
+// Returns true if the id is an infix operator or a special form.
+func IsOperator(id string) bool {
+	switch id {
+	case "import!", "import":
+		return true
+	}
+
+	for _, operator := range InfixPrecedence {
+		if operator == id {
+			return true
+		}
+	}
+
+	return false
+}
 
 // Get the precedence of a token.
 func precedenceOf(token Token) float32 {
+	return operatorPrecedence(token.Value)
+}
+
+// Get the precedence of an infix operator.
+func operatorPrecedence(operator string) float32 {
 	for precedence, tokenType := range InfixPrecedence {
-		if token.Value == tokenType {
+		if operator == tokenType {
 			return float32(precedence)
 		}
 	}
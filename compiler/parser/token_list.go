@@ -49,3 +49,30 @@ func (tokens tokenList) AfterNext(tokenType string) *Token {
 
 	return nil
 }
+
+// Returns a pointer to the first token _after_ the closing token
+// that matches the opening token at the start of the list.
+//
+// For example, in the token list `(a(b)) { c }`
+// tokenList.AfterMatching("bracket_open", "bracket_close") would point to `{`
+func (tokens tokenList) AfterMatching(openType string, closeType string) *Token {
+	depth := 0
+	for idx, token := range tokens {
+		switch token.Type {
+		case openType:
+			depth += 1
+		case closeType:
+			depth -= 1
+		}
+
+		if depth == 0 {
+			if idx+1 == tokens.Length() {
+				return nil
+			} else {
+				return &tokens[idx+1]
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,431 @@
+// Package resolver checks the names used in fn programs before they are run,
+// reporting names that are undefined, defined twice, unused or shadowed.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/runtime"
+	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
+)
+
+type Severity int
+
+const (
+	Error   Severity = iota // The program will fail when it reaches this.
+	Warning                 // The program will run, but is probably wrong.
+)
+
+// The kinds of problem, so tools such as fn lint can pick out the ones they report.
+type Kind int
+
+const (
+	Undefined Kind = iota
+	Redefined
+	Unused
+	Shadowed
+	BadImport
+)
+
+// A Diagnostic is a problem found by the resolver.
+type Diagnostic struct {
+	Severity Severity
+	Kind     Kind
+	Line     int // The line of the expression with the problem.
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Severity == Warning {
+		return "Warning: " + d.Message
+	}
+
+	return "Error: " + d.Message
+}
+
+// A function whose body is resolved once its enclosing scope is complete,
+// as functions can use names defined after them.
+type deferredFunction struct {
+	expr  FunctionPrototypeExpression
+	scope *scope
+	line  int
+}
+
+type resolver struct {
+	diagnostics    []Diagnostic
+	line           int // The line of the call being resolved.
+	deferred       []deferredFunction
+	functionScopes []*scope
+	importing      map[string]bool // Files being imported, to stop import cycles.
+}
+
+// Returns the problems with the names in the expressions.
+func Resolve(exprs []Expression) []Diagnostic {
+	r := resolver{importing: map[string]bool{}}
+
+	builtins := newScope(nil, builtinScope)
+	for _, name := range runtime.BuiltinNames() {
+		builtins.add(&definition{name: name})
+	}
+
+	r.resolveBody(exprs, newScope(builtins, fileScope))
+
+	for len(r.deferred) > 0 {
+		next := r.deferred[0]
+		r.deferred = r.deferred[1:]
+		r.line = next.line
+		r.resolveFunctionBody(next.expr, next.scope)
+	}
+
+	// Nested functions can use the names of the functions around them,
+	// so unused names are only known once every function is resolved.
+	for _, s := range r.functionScopes {
+		for _, name := range s.order {
+			if def := s.names[name]; def.reportUnused && !def.used && name[0] != '_' {
+				r.line = def.line
+				r.warn(Unused, "%s is defined but never used", name)
+			}
+		}
+	}
+
+	return r.diagnostics
+}
+
+// Returns true if any of the diagnostics are errors.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *resolver) error(kind Kind, format string, args ...interface{}) {
+	r.report(Error, kind, fmt.Sprintf(format, args...))
+}
+
+func (r *resolver) warn(kind Kind, format string, args ...interface{}) {
+	r.report(Warning, kind, fmt.Sprintf(format, args...))
+}
+
+func (r *resolver) report(severity Severity, kind Kind, message string) {
+	r.diagnostics = append(r.diagnostics, Diagnostic{Severity: severity, Kind: kind, Line: r.line, Message: message})
+}
+
+func (r *resolver) resolveBody(exprs []Expression, s *scope) {
+	for _, expr := range exprs {
+		r.resolve(expr, s)
+	}
+}
+
+// Resolves the names used by the expression.
+func (r *resolver) resolve(expr Expression, s *scope) {
+	switch expr.(type) {
+	case IdentifierExpression:
+		r.use(expr.(IdentifierExpression).Name, s)
+
+	case FunctionPrototypeExpression:
+		function := expr.(FunctionPrototypeExpression)
+
+		// Defaults are evaluated when the function is defined.
+		for _, arg := range function.Arguments {
+			if arg.Default != nil {
+				r.resolve(arg.Default, s)
+			}
+		}
+
+		r.deferred = append(r.deferred, deferredFunction{expr: function, scope: s, line: r.line})
+
+	case BlockExpression:
+		r.resolveBody(expr.(BlockExpression).Body, newScope(s, blockScope))
+
+	case ExtendExpression:
+		extend := expr.(ExtendExpression)
+		r.resolve(extend.Base, s)
+
+		extendBody := newScope(s, extendScope)
+		extendBody.add(&definition{name: "super"})
+		r.resolveBody(extend.Body.Body, extendBody)
+
+	case ConditionalExpression:
+		r.resolveConditional(expr.(ConditionalExpression), s)
+
+	case FunctionCallExpression:
+		outer := r.line
+		r.line = expr.(FunctionCallExpression).Line
+		r.resolveCall(expr.(FunctionCallExpression), s)
+		r.line = outer
+
+	case NamedArgumentExpression:
+		r.resolve(expr.(NamedArgumentExpression).Value, s)
+	}
+}
+
+// Marks the name as used, reporting it if it is not defined.
+func (r *resolver) use(name string, s *scope) {
+	if def := s.lookup(name); def != nil {
+		def.used = true
+		return
+	}
+
+	if s.isOpen() {
+		return
+	}
+
+	if suggestion := closestName(name, s.visibleNames()); suggestion != "" {
+		r.error(Undefined, "%s is not defined (did you mean %s?)", name, suggestion)
+	} else {
+		r.error(Undefined, "%s is not defined", name)
+	}
+}
+
+// Defines the name in the scope, reporting it if it is already defined.
+func (r *resolver) define(name string, s *scope, reportUnused bool) {
+	for _, definingScope := range s.definingScopes() {
+		if _, ok := definingScope.names[name]; ok {
+			r.error(Redefined, "%s is already defined", name)
+			return
+		}
+	}
+
+	if s.kind == functionScope && s.isOuterName(name) {
+		r.warn(Shadowed, "%s shadows an outer definition", name)
+	}
+
+	s.add(&definition{name: name, line: r.line, reportUnused: reportUnused && s.kind == functionScope})
+}
+
+// Defines each name in the pattern.
+func (r *resolver) definePattern(pattern Expression, s *scope, reportUnused bool) {
+	switch pattern.(type) {
+	case IdentifierExpression:
+		r.define(pattern.(IdentifierExpression).Name, s, reportUnused)
+
+	case TypedPatternExpression:
+		r.definePattern(pattern.(TypedPatternExpression).Pattern, s, reportUnused)
+
+	case ListPatternExpression:
+		for _, item := range pattern.(ListPatternExpression).Items {
+			r.definePattern(item, s, reportUnused)
+		}
+
+	case BlockPatternExpression:
+		for _, name := range pattern.(BlockPatternExpression).Names {
+			r.define(name.Name, s, reportUnused)
+		}
+	}
+}
+
+// Resolves the body of a function in a new scope holding its arguments.
+func (r *resolver) resolveFunctionBody(expr FunctionPrototypeExpression, s *scope) {
+	fnScope := newScope(s, functionScope)
+	for _, arg := range expr.Arguments {
+		r.definePattern(arg.Pattern, fnScope, false)
+	}
+
+	r.resolveBody(expr.Body.Body, fnScope)
+	r.functionScopes = append(r.functionScopes, fnScope)
+}
+
+// Resolves each branch of a conditional.
+// Branches define into the enclosing scope, so their definitions
+// are added to it once every branch is resolved.
+func (r *resolver) resolveConditional(expr ConditionalExpression, s *scope) {
+	if expr.Subject != nil {
+		r.resolve(expr.Subject, s)
+	}
+
+	branches := []*scope{}
+	for _, branch := range expr.Branches {
+		if pattern, ok := branch.Condition.(VariantPatternExpression); ok {
+			r.use(pattern.Name, s)
+
+			matchScope := newScope(s, functionScope)
+			for _, field := range pattern.Fields {
+				r.definePattern(field, matchScope, false)
+			}
+
+			r.resolveBody(branch.Body.Body, matchScope)
+			continue
+		}
+
+		r.resolve(branch.Condition, s)
+
+		branchBody := newScope(s, branchScope)
+		r.resolveBody(branch.Body.Body, branchBody)
+		branches = append(branches, branchBody)
+	}
+
+	for _, branch := range branches {
+		for _, name := range branch.order {
+			if _, ok := s.names[name]; !ok {
+				s.add(branch.names[name])
+			}
+		}
+	}
+}
+
+// Resolves a function call, including infix operators and special forms.
+func (r *resolver) resolveCall(expr FunctionCallExpression, s *scope) {
+	id, args := expr.Identifier.Name, expr.Arguments
+
+	switch id {
+	case "=":
+		// The value is executed before the names are defined.
+		r.resolve(args[1], s)
+		r.definePattern(args[0], s, true)
+		return
+
+	case ".":
+		// Attributes are looked up on the value, which is only known at runtime,
+		// but calls such as `x.foo(a)` take their arguments from this scope.
+		r.resolve(args[0], s)
+		r.resolveChain(args[1], s)
+		return
+
+	case "with", "and", "or":
+		r.resolveBody(args, s)
+		return
+
+	case "Union":
+		for _, arg := range args {
+			switch arg.(type) {
+			case IdentifierExpression:
+				r.define(arg.(IdentifierExpression).Name, s, false)
+			case FunctionCallExpression:
+				r.define(arg.(FunctionCallExpression).Identifier.Name, s, false)
+			}
+		}
+		return
+
+	case "import!":
+		r.resolveImport(args[0].(StringExpression).Value, s)
+		return
+
+	case "import":
+		if _, err := ioutil.ReadFile(args[0].(StringExpression).Value); err != nil {
+			r.error(BadImport, "Cannot import %s: %s", args[0].(StringExpression).Value, err.Error())
+		}
+		return
+	}
+
+	r.use(id, s)
+	r.resolveBody(args, s)
+}
+
+// Resolves the arguments of the calls in a chain such as `x.foo(a).bar(b)`,
+// which all come from this scope.
+func (r *resolver) resolveChain(expr Expression, s *scope) {
+	call, ok := expr.(FunctionCallExpression)
+	if !ok {
+		return
+	}
+
+	if call.Identifier.Name == "." {
+		r.resolveChain(call.Arguments[0], s)
+		r.resolveChain(call.Arguments[1], s)
+	} else if !IsOperator(call.Identifier.Name) {
+		r.resolveBody(call.Arguments, s)
+	}
+}
+
+// Defines the top-level names of the file in the scope.
+func (r *resolver) resolveImport(fileName string, s *scope) {
+	names, err := r.namesOfFile(fileName)
+	if err != nil {
+		r.error(BadImport, "Cannot import %s: %s", fileName, err.Error())
+		return
+	}
+
+	for _, name := range names {
+		r.define(name, s, false)
+	}
+}
+
+// Returns the names defined at the top level of the file.
+func (r *resolver) namesOfFile(fileName string) ([]string, error) {
+	if r.importing[fileName] {
+		return nil, errors.New("the file imports itself")
+	}
+
+	r.importing[fileName] = true
+	defer delete(r.importing, fileName)
+
+	file, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs, err := Parse(Tokenise(string(file)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Names are collected by resolving the file with a resolver of its own,
+	// so its problems are not reported here.
+	imported := resolver{importing: r.importing}
+	builtins := newScope(nil, builtinScope)
+	for _, name := range runtime.BuiltinNames() {
+		builtins.add(&definition{name: name})
+	}
+
+	fileNames := newScope(builtins, fileScope)
+	imported.resolveBody(exprs, fileNames)
+
+	return fileNames.order, nil
+}
+
+// Returns the name closest to the given name, if any is close enough
+// to be a likely typo. Short names need to be closer.
+func closestName(name string, names []string) string {
+	closest, closestDistance := "", min(2, len(name)/2)+1
+	for _, candidate := range names {
+		if distance := editDistance(name, candidate); distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+
+	return closest
+}
+
+// Returns the number of single character edits needed to turn a into b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+
+	return smallest
+}
@@ -0,0 +1,171 @@
+package resolver
+
+import (
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/tokeniser"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Returns the diagnostics for the given code, as strings.
+func diagnosticsFor(code string) []string {
+	exprs, err := Parse(tokeniser.Tokenise(code))
+	if err != nil {
+		panic(err)
+	}
+
+	messages := []string{}
+	for _, diagnostic := range Resolve(exprs) {
+		messages = append(messages, diagnostic.String())
+	}
+
+	return messages
+}
+
+func TestResolver(t *testing.T) {
+	Convey("Undefined names", t, func() {
+
+		Convey("are reported", func() {
+			So(diagnosticsFor("x = y"), ShouldResemble, []string{"Error: y is not defined"})
+		})
+
+		Convey("suggest a close name", func() {
+			So(diagnosticsFor("count = 1; print(cuont)"), ShouldResemble, []string{
+				"Error: cuont is not defined (did you mean count?)",
+			})
+		})
+
+		Convey("are reported when called", func() {
+			So(diagnosticsFor("prnt(1)"), ShouldResemble, []string{
+				"Error: prnt is not defined (did you mean print?)",
+			})
+		})
+
+		Convey("are not reported for builtins", func() {
+			So(diagnosticsFor("print(List(1, 2))"), ShouldBeEmpty)
+		})
+
+		Convey("are not reported for names defined after a function", func() {
+			So(diagnosticsFor("f = () { g() }; g = () { 1 }; f()"), ShouldBeEmpty)
+		})
+
+		Convey("are reported for names used before they are defined", func() {
+			So(diagnosticsFor("x = y; y = 1"), ShouldResemble, []string{"Error: y is not defined"})
+		})
+
+		Convey("are not reported for attributes", func() {
+			So(diagnosticsFor("b = { x = 1 }; b.x; b.foo(1)"), ShouldBeEmpty)
+		})
+
+		Convey("are reported in the arguments of attribute calls", func() {
+			So(diagnosticsFor("b = { f = (a) { a } }; b.f(z)"), ShouldResemble, []string{"Error: z is not defined"})
+		})
+
+		Convey("are reported in the arguments of chained calls", func() {
+			So(diagnosticsFor("b = { f = (a) { a } }; b.f(1).g(z)"), ShouldResemble, []string{"Error: z is not defined"})
+		})
+
+		Convey("are not reported in the body of an extend", func() {
+			So(diagnosticsFor("A = { x = 1 }; B = extend(A) { y = () { x } }"), ShouldBeEmpty)
+		})
+
+		Convey("are reported for unknown variants", func() {
+			So(diagnosticsFor("Shape = Union(Circle(r)); s = Circle(1); when s { Square(l) { l } true { 0 } }"), ShouldResemble, []string{
+				"Error: Square is not defined",
+			})
+		})
+
+	})
+
+	Convey("Duplicate definitions", t, func() {
+
+		Convey("are reported", func() {
+			So(diagnosticsFor("x = 1; x = 2"), ShouldResemble, []string{"Error: x is already defined"})
+		})
+
+		Convey("are reported when a branch defines an existing name", func() {
+			So(diagnosticsFor("x = 1; when { true { x = 2 } }"), ShouldResemble, []string{"Error: x is already defined"})
+		})
+
+		Convey("are not reported for names defined in separate branches", func() {
+			So(diagnosticsFor("when { false { x = 1 } true { x = 2 } }; x"), ShouldBeEmpty)
+		})
+
+		Convey("are not reported for names in separate blocks", func() {
+			So(diagnosticsFor("a = { x = 1 }; b = { x = 2 }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Unused definitions", t, func() {
+
+		Convey("are reported in functions", func() {
+			So(diagnosticsFor("f = () { x = 1; 2 }; f()"), ShouldResemble, []string{
+				"Warning: x is defined but never used",
+			})
+		})
+
+		Convey("are not reported if used by a nested function", func() {
+			So(diagnosticsFor("f = () { x = 1; () { x } }; f()"), ShouldBeEmpty)
+		})
+
+		Convey("are not reported if used in a chained call", func() {
+			So(diagnosticsFor("f = () { x = 1; JSON.parse(String(x)).name }; f()"), ShouldBeEmpty)
+		})
+
+		Convey("are not reported for names starting with _", func() {
+			So(diagnosticsFor("f = () { _x = 1; 2 }; f()"), ShouldBeEmpty)
+		})
+
+		Convey("are not reported for arguments or top-level names", func() {
+			So(diagnosticsFor("x = 1; f = (a) { 2 }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Shadowing", t, func() {
+
+		Convey("is reported for arguments", func() {
+			So(diagnosticsFor("x = 1; f = (x) { x }; f(x)"), ShouldResemble, []string{
+				"Warning: x shadows an outer definition",
+			})
+		})
+
+		Convey("is not reported for builtins", func() {
+			So(diagnosticsFor("f = (print) { print }; f(1)"), ShouldBeEmpty)
+		})
+
+		Convey("is not reported for attributes", func() {
+			So(diagnosticsFor("x = 1; b = { x = 2 }"), ShouldBeEmpty)
+		})
+
+	})
+
+	Convey("Imports", t, func() {
+
+		Convey("report missing files", func() {
+			So(diagnosticsFor("import!(\"missing.fn\")"), ShouldResemble, []string{
+				"Error: Cannot import missing.fn: open missing.fn: no such file or directory",
+			})
+		})
+
+	})
+
+	Convey("Diagnostics", t, func() {
+
+		Convey("have the kind and line of their problem", func() {
+			exprs, _ := Parse(tokeniser.Tokenise("x = 1\nf = () {\n  y = 1\n  print(z)\n}\nx = 2"))
+			diagnostics := Resolve(exprs)
+
+			kinds, lines := []Kind{}, []int{}
+			for _, diagnostic := range diagnostics {
+				kinds = append(kinds, diagnostic.Kind)
+				lines = append(lines, diagnostic.Line)
+			}
+
+			So(kinds, ShouldResemble, []Kind{Redefined, Undefined, Unused})
+			So(lines, ShouldResemble, []int{6, 4, 3})
+		})
+
+	})
+}
@@ -0,0 +1,94 @@
+package resolver
+
+// The kinds of scope, which differ in how their definitions are reported.
+type scopeKind int
+
+const (
+	builtinScope  scopeKind = iota
+	fileScope               // The top level of a file.
+	blockScope              // A block; its definitions are attributes, so are never unused.
+	functionScope           // A function body, or a branch matching a variant.
+	branchScope             // A branch of a when, which defines into the enclosing scope.
+	extendScope             // The body of an extend, which can see the attributes of its base.
+)
+
+// A definition of a name.
+type definition struct {
+	name string
+	line int
+	used bool
+	// Arguments and attributes are not reported when unused.
+	reportUnused bool
+}
+
+// A scope of definitions, mirroring the scopes created at runtime.
+type scope struct {
+	parent *scope
+	kind   scopeKind
+	names  map[string]*definition
+	order  []string // Names in the order they were defined, for stable reports.
+}
+
+func newScope(parent *scope, kind scopeKind) *scope {
+	return &scope{parent: parent, kind: kind, names: map[string]*definition{}}
+}
+
+// Returns the definition of the name, or nil if it is not visible.
+func (s *scope) lookup(name string) *definition {
+	for current := s; current != nil; current = current.parent {
+		if def, ok := current.names[name]; ok {
+			return def
+		}
+	}
+
+	return nil
+}
+
+// Returns true if the name may be defined by something the resolver cannot see,
+// such as the base of an extend.
+func (s *scope) isOpen() bool {
+	for current := s; current != nil; current = current.parent {
+		if current.kind == extendScope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the scope that definitions are added to.
+// Branches define into the enclosing scope at runtime,
+// so their definitions clash with those already there.
+func (s *scope) definingScopes() []*scope {
+	if s.kind == branchScope {
+		return append([]*scope{s}, s.parent.definingScopes()...)
+	}
+
+	return []*scope{s}
+}
+
+// Returns true if the name is defined in an enclosing scope written by the user.
+func (s *scope) isOuterName(name string) bool {
+	for current := s.parent; current != nil && current.kind != builtinScope; current = current.parent {
+		if _, ok := current.names[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the names visible from the scope.
+func (s *scope) visibleNames() []string {
+	names := []string{}
+	for current := s; current != nil; current = current.parent {
+		names = append(names, current.order...)
+	}
+
+	return names
+}
+
+func (s *scope) add(def *definition) {
+	s.names[def.name] = def
+	s.order = append(s.order, def.name)
+}
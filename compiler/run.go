@@ -6,21 +6,82 @@ import (
 	. "github.com/jonnyarnold/fn-go/compiler/runtime"
 	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
 	"io/ioutil"
+	"os"
 )
 
-func Run(fileName string) {
-	file, _ := ioutil.ReadFile(fileName)
+// The exit codes of fn run and fn eval.
+// exit(code) in a program exits with its own code.
+const (
+	ExitOK           = 0
+	ExitRuntimeError = 1
+	ExitSyntaxError  = 2
+	ExitMissingFile  = 3
+)
+
+// Runs the given file, or standard input if the file name is "-".
+// If output is "text" or "json", the value of the program is printed in that format.
+// Returns the exit code.
+func Run(fileName string, output string) int {
+	if !isOutputFormat(output) {
+		return unknownOutput(output)
+	}
+
+	var (
+		file []byte
+		err  error
+	)
+
+	if fileName == "-" {
+		file, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		file, err = ioutil.ReadFile(fileName)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitMissingFile
+	}
 
-	tokens := Tokenise(string(file))
+	return execute(string(file), output)
+}
+
+// Runs the code, printing its value in the output format (text or json).
+// Returns the exit code.
+func Eval(code string, output string) int {
+	if output == "" {
+		output = "text"
+	}
+
+	if !isOutputFormat(output) {
+		return unknownOutput(output)
+	}
+
+	return execute(code, output)
+}
+
+// Returns true if the output format is known,
+// so a wrong format is reported before the program runs.
+func isOutputFormat(output string) bool {
+	return output == "" || output == "text" || output == "json"
+}
+
+func unknownOutput(output string) int {
+	fmt.Fprintf(os.Stderr, "Unknown output format %s\n", output)
+	return ExitRuntimeError
+}
+
+func execute(code string, output string) int {
+	tokens := Tokenise(code)
 
 	// for _, token := range tokens {
 	// 	fmt.Println(token)
 	// }
 
-	expressions, errors := Parse(tokens)
+	expressions, err := Parse(tokens)
 
-	if errors != nil {
-		fmt.Println(errors)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitSyntaxError
 	}
 
 	// for _, expr := range expressions {
@@ -29,7 +90,42 @@ func Run(fileName string) {
 
 	result := Execute(expressions)
 
+	if exit, ok := result.Error.(ExitError); ok {
+		return exit.Code
+	}
+
 	if result.Error != nil {
-		fmt.Println(result.Error)
+		fmt.Fprintln(os.Stderr, result.Error)
+		return ExitRuntimeError
 	}
+
+	return printValue(result, output)
+}
+
+// Prints the value of the result in the output format.
+// Nothing is printed if no format is given.
+func printValue(result EvalResult, output string) int {
+	switch output {
+	case "":
+		return ExitOK
+
+	case "text":
+		if result.Value != nil {
+			fmt.Println(result.Value.String())
+		}
+
+		return ExitOK
+
+	case "json":
+		json, err := ToJSON(result.Value, "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitRuntimeError
+		}
+
+		fmt.Println(json)
+		return ExitOK
+	}
+
+	return unknownOutput(output)
 }
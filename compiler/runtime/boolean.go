@@ -35,11 +35,13 @@ func FnBool(value bool) fnBool {
 
 // The definition of truth.
 //
-// We follow Ruby's convention - only false is false.
+// We follow Ruby's convention - only false and nil are false.
 func AsBool(value fnScope) bool {
 	switch value.(type) {
 	case fnBool:
 		return value.(fnBool).value
+	case nothing:
+		return false
 	}
 
 	return value != nil
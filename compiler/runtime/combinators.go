@@ -0,0 +1,77 @@
+package runtime
+
+import (
+	"errors"
+)
+
+// Returns a function with the given arguments already applied.
+// partial(f, a, b) is equivalent to `(...rest) { f(a, b, ...rest) }`.
+func partial(args []fnScope) (fnScope, error) {
+	function, given := args[0], args[1].(list).Items
+
+	// User-defined and built-in functions keep their remaining argument names.
+	if fs, ok := function.(functionScope); ok {
+		applied, err := fs.partial(given)
+		if err != nil {
+			return nil, err
+		}
+
+		return applied, nil
+	}
+
+	return fnWithRest([]string{}, "args", func(restArgs []fnScope) (fnScope, error) {
+		allArgs := append([]fnScope{}, given...)
+		allArgs = append(allArgs, restArgs[0].(list).Items...)
+		return function.Call(allArgs)
+	}), nil
+}
+
+// Returns a function that applies each function from right to left.
+// compose(f, g)(x) is equivalent to f(g(x)).
+func compose(args []fnScope) (fnScope, error) {
+	functions := args[0].(list).Items
+	if len(functions) == 0 {
+		return nil, errors.New("compose() needs at least one function")
+	}
+
+	reversed := make([]fnScope, len(functions))
+	for idx, function := range functions {
+		reversed[len(functions)-1-idx] = function
+	}
+
+	return chain(reversed), nil
+}
+
+// Returns a function that applies each function from left to right.
+// pipe(f, g)(x) is equivalent to g(f(x)).
+func pipe(args []fnScope) (fnScope, error) {
+	functions := args[0].(list).Items
+	if len(functions) == 0 {
+		return nil, errors.New("pipe() needs at least one function")
+	}
+
+	return chain(functions), nil
+}
+
+// Returns a function that passes its argument through each function in turn.
+func chain(functions []fnScope) functionScope {
+	return fn([]string{"value"}, func(args []fnScope) (fnScope, error) {
+		value := args[0]
+
+		var err error
+		for _, function := range functions {
+			value, err = function.Call([]fnScope{value})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return value, nil
+	})
+}
+
+// The pipeline operator.
+// `value |> f` is equivalent to f(value).
+func pipeline(args []fnScope) (fnScope, error) {
+	return args[1].Call([]fnScope{args[0]})
+}
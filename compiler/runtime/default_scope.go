@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 )
 
 type defaultScope struct {
@@ -53,24 +55,70 @@ func (scope defaultScope) Value() interface{} {
 // The top scope is the default scope used by files and REPLs.
 var topScope = defaultScope{
 	definitions: defMap{
+		"nil": Nothing,
+
 		"Boolean": fn([]string{"obj"}, asBool),
 		"List":    fnList{},
-		"String":  fn([]string{"obj"}, callOnFirstArgument("asString")),
+		"String":  fn([]string{"obj"}, asString),
+		"Record":  fn([]string{"name", "fields"}, fnRecord),
 
 		"not": fn([]string{"a"}, not),
 		"and": fn([]string{"a", "b"}, and),
 		"or":  fn([]string{"a", "b"}, or),
 		"eq":  fn([]string{"a", "b"}, eq),
 
-		"print": fn([]string{"a"}, fnPrint),
+		"same": fn([]string{"a", "b"}, fnSame),
+		"hash": fn([]string{"value"}, fnHash),
+
+		"print":    fnWithOptions([]string{"sep", "end"}, printDefaults, "values", fnPrint),
+		"eprint":   fnWithOptions([]string{"sep", "end"}, printDefaults, "values", fnEprint),
+		"write":    fnWithRest([]string{}, "values", fnWrite),
+		"readLine": fn([]string{}, fnReadLine),
+		"readAll":  fn([]string{}, fnReadAll),
+		"format":   fnWithRest([]string{"template"}, "values", fnFormat),
+
+		"exit": fn([]string{"code"}, fnExit),
+
+		"System": system{},
+		"File":   fileModule{},
+		"JSON":   jsonModule{},
+
+		"partial": fnWithRest([]string{"f"}, "args", partial),
+		"compose": fnWithRest([]string{}, "functions", compose),
+		"pipe":    fnWithRest([]string{}, "functions", pipe),
+		"|>":      fn([]string{"value", "f"}, pipeline),
 
 		"+": fn([]string{"a", "b"}, callOnFirstArgument("+")),
 		"-": fn([]string{"a", "b"}, callOnFirstArgument("-")),
 		"*": fn([]string{"a", "b"}, callOnFirstArgument("*")),
 		"/": fn([]string{"a", "b"}, callOnFirstArgument("/")),
+
+		"lessThan": fn([]string{"a", "b"}, callOnFirstArgument("lessThan")),
+		"moreThan": fn([]string{"a", "b"}, moreThan),
 	},
 }
 
+// Built-in functions are named after their definitions,
+// so errors can refer to them.
+func init() {
+	for id, value := range topScope.definitions {
+		if function, ok := value.(functionScope); ok {
+			topScope.definitions[id] = function.named(id)
+		}
+	}
+}
+
+// Returns the names of the built-in definitions, in order.
+func BuiltinNames() []string {
+	names := []string{}
+	for id := range topScope.definitions {
+		names = append(names, id)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
 func DefaultScope() Scope {
 	var topFnScope fnScope = topScope
 
@@ -80,6 +128,14 @@ func DefaultScope() Scope {
 	}
 }
 
+// Returns a default scope whose definitions can be replaced,
+// calling redefined with the name of each one replaced.
+func RedefinableScope(redefined func(id string)) Scope {
+	scope := DefaultScope()
+	scope.redefined = redefined
+	return scope
+}
+
 func asBool(args []fnScope) (fnScope, error) {
 	return FnBool(AsBool(args[0])), nil
 }
@@ -88,25 +144,42 @@ func not(args []fnScope) (fnScope, error) {
 	return FnBool(!AsBool(args[0])), nil
 }
 
-func callOnFirstArgument(op string) fnFunc {
-	return func(args []fnScope) (fnScope, error) {
-		return args[0].Definitions()[op].Call(args[1:2])
-	}
-}
-
+// Returns the first argument if it is false, or the second otherwise.
+//
+// The infix operator (`a and b`) is executed by execAnd,
+// which does not evaluate b if a is false.
 func and(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(args[0]) && AsBool(args[1])), nil
+	return andValues(args[0], func() (fnScope, error) { return args[1], nil })
 }
 
+// Returns the first argument if it is true, or the second otherwise.
+//
+// The infix operator (`a or b`) is executed by execOr,
+// which does not evaluate b if a is true.
 func or(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(args[0]) || AsBool(args[1])), nil
+	return orValues(args[0], func() (fnScope, error) { return args[1], nil })
 }
 
-func eq(args []fnScope) (fnScope, error) {
-	return FnBool(args[0].Value() == args[1].Value()), nil
+// Returned by `exit(code)` to end the program with the exit code.
+type ExitError struct {
+	Code int
 }
 
-func fnPrint(args []fnScope) (fnScope, error) {
-	fmt.Println(args[0].String())
-	return nil, nil
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit(%d)", e.Code)
+}
+
+func fnExit(args []fnScope) (fnScope, error) {
+	code, ok := args[0].(number)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("exit expects a number, got %s", args[0].String()))
+	}
+
+	// Exit codes are a byte, so anything else would be silently changed.
+	value := code.AsFloat()
+	if value != math.Trunc(value) || value < 0 || value > 255 {
+		return nil, errors.New(fmt.Sprintf("exit expects a whole number from 0 to 255, got %s", code.String()))
+	}
+
+	return nil, ExitError{Code: int(value)}
 }
@@ -0,0 +1,227 @@
+package runtime
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Returns true if the two values are structurally equal.
+//
+// Lists are equal if their items are equal, and blocks are equal
+// if they have the same definitions with equal values,
+// including those of any blocks they extend.
+// Functions are only equal to themselves.
+func equal(a fnScope, b fnScope) bool {
+	return equalSeen(a, b, map[[2]uintptr]bool{})
+}
+
+// Compares a and b, tracking the blocks already being compared
+// so that blocks which contain themselves do not recurse forever.
+func equalSeen(a fnScope, b fnScope, seen map[[2]uintptr]bool) bool {
+	switch a.(type) {
+	case list:
+		other, ok := b.(list)
+		if !ok || len(a.(list).Items) != len(other.Items) {
+			return false
+		}
+
+		for idx, item := range a.(list).Items {
+			if !equalSeen(item, other.Items[idx], seen) {
+				return false
+			}
+		}
+
+		return true
+
+	case Scope:
+		other, ok := b.(Scope)
+		if !ok {
+			return false
+		}
+
+		pair := [2]uintptr{identity(a.(Scope).definitions), identity(other.definitions)}
+		if seen[pair] {
+			return true
+		}
+		seen[pair] = true
+
+		return equalDefinitions(a.(Scope).attributes(), other.attributes(), seen)
+
+	case record:
+		other, ok := b.(record)
+		return ok && a.(record).recordType.id == other.recordType.id &&
+			equalDefinitions(a.(record).values, other.values, seen)
+
+	case recordType:
+		other, ok := b.(recordType)
+		return ok && a.(recordType).id == other.id
+
+	case functionScope:
+		other, ok := b.(functionScope)
+		return ok && a.(functionScope).id == other.id
+
+	case number, fnString, fnBool, nothing:
+		return reflect.TypeOf(a) == reflect.TypeOf(b) && a.Value() == b.Value()
+	}
+
+	return same(a, b)
+}
+
+// Returns true if both definition maps have the same keys with equal values.
+func equalDefinitions(a defMap, b defMap, seen map[[2]uintptr]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for id, value := range a {
+		otherValue, ok := b[id]
+		if !ok || !equalSeen(value, otherValue, seen) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns true if a and b are the same value.
+//
+// Blocks and lists are only the same as themselves;
+// numbers, strings and booleans are the same if they are equal.
+func same(a fnScope, b fnScope) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+
+	switch a.(type) {
+	case list:
+		// Each list has its own array of items, so compare the arrays.
+		items, otherItems := a.(list).Items, b.(list).Items
+		return len(items) == len(otherItems) && cap(items) > 0 &&
+			reflect.ValueOf(items).Pointer() == reflect.ValueOf(otherItems).Pointer()
+
+	case Scope:
+		return identity(a.(Scope).definitions) == identity(b.(Scope).definitions)
+
+	case defaultScope:
+		return identity(a.(defaultScope).definitions) == identity(b.(defaultScope).definitions)
+
+	case record:
+		return identity(a.(record).values) == identity(b.(record).values)
+
+	case recordType:
+		return a.(recordType).id == b.(recordType).id
+
+	case unionType:
+		return identity(a.(unionType).variants) == identity(b.(unionType).variants)
+
+	case functionScope:
+		return a.(functionScope).id == b.(functionScope).id
+
+	case number, fnString, fnBool, nothing:
+		return a.Value() == b.Value()
+
+	// There is only one of each module.
+	case system, fileModule, jsonModule, fnList:
+		return true
+	}
+
+	return false
+}
+
+// Returns the address of the map, which identifies a block.
+func identity(definitions defMap) uintptr {
+	return reflect.ValueOf(definitions).Pointer()
+}
+
+// Returns a hash of the value, such that equal values have equal hashes.
+func hashOf(value fnScope) uint64 {
+	hash := fnv.New64a()
+	writeHash(hash, value, map[uintptr]bool{})
+	return hash.Sum64()
+}
+
+// Writes the contents of the value to the hash.
+// Each type writes a distinct prefix, so `1` and `"1"` hash differently.
+func writeHash(hash io.Writer, value fnScope, seen map[uintptr]bool) {
+	switch value.(type) {
+	case number:
+		io.WriteString(hash, "number:")
+		binary.Write(hash, binary.LittleEndian, math.Float64bits(value.(number).AsFloat()))
+
+	case fnString:
+		io.WriteString(hash, "string:"+value.(fnString).value)
+
+	case fnBool:
+		io.WriteString(hash, "bool:"+value.String())
+
+	case nothing:
+		io.WriteString(hash, "nil")
+
+	case list:
+		io.WriteString(hash, "list:")
+		for _, item := range value.(list).Items {
+			writeHash(hash, item, seen)
+			io.WriteString(hash, ",")
+		}
+
+	case Scope:
+		io.WriteString(hash, "block:")
+
+		// Blocks that contain themselves stop hashing at the repeat.
+		definitions := value.(Scope).definitions
+		if seen[identity(definitions)] {
+			return
+		}
+		seen[identity(definitions)] = true
+		defer delete(seen, identity(definitions))
+
+		// Maps are unordered, so sort the names for a stable hash.
+		ids := []string{}
+		attributes := value.(Scope).attributes()
+		for id := range attributes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			io.WriteString(hash, id+"=")
+			writeHash(hash, attributes[id], seen)
+			io.WriteString(hash, ",")
+		}
+
+	case record:
+		rec := value.(record)
+		io.WriteString(hash, "record:")
+		binary.Write(hash, binary.LittleEndian, rec.recordType.id)
+
+		for _, field := range rec.recordType.Fields {
+			io.WriteString(hash, field+"=")
+			writeHash(hash, rec.values[field], seen)
+			io.WriteString(hash, ",")
+		}
+
+	case recordType:
+		io.WriteString(hash, "recordType:")
+		binary.Write(hash, binary.LittleEndian, value.(recordType).id)
+
+	case functionScope:
+		io.WriteString(hash, "function:")
+		binary.Write(hash, binary.LittleEndian, value.(functionScope).id)
+
+	default:
+		io.WriteString(hash, value.String())
+	}
+}
+
+func fnSame(args []fnScope) (fnScope, error) {
+	return FnBool(same(args[0], args[1])), nil
+}
+
+// Hashes are truncated to 53 bits, so they are exact as numbers.
+func fnHash(args []fnScope) (fnScope, error) {
+	return NumberFromFloat(float64(hashOf(args[0]) & (1<<53 - 1))), nil
+}
@@ -6,6 +6,10 @@ import (
 	. "github.com/jonnyarnold/fn-go/compiler/parser"
 )
 
+// In strict mode, definitions cannot be added to a block
+// from outside it (e.g. `block.foo = 1`).
+var Strict = false
+
 // The result of an evaluation
 type EvalResult struct {
 	Value fnScope
@@ -53,6 +57,8 @@ func exec(expr Expression, scope fnScope) EvalResult {
 		return execFunctionCall(expr.(FunctionCallExpression), scope)
 	case ConditionalExpression:
 		return execConditional(expr.(ConditionalExpression), scope)
+	case ExtendExpression:
+		return execExtend(expr.(ExtendExpression), scope)
 	}
 
 	ignore(expr)
@@ -60,7 +66,11 @@ func exec(expr Expression, scope fnScope) EvalResult {
 }
 
 func execIdentifier(expr IdentifierExpression, scope fnScope) EvalResult {
-	value := scope.Definitions()[expr.Name]
+	value, err := lookup(scope, expr.Name)
+	if err != nil {
+		return EvalResult{Error: err}
+	}
+
 	if value == nil {
 		return EvalResult{Error: errors.New(fmt.Sprintf("%s is not defined.", expr.Name))}
 	}
@@ -68,6 +78,18 @@ func execIdentifier(expr IdentifierExpression, scope fnScope) EvalResult {
 	return EvalResult{Value: value, Scope: scope}
 }
 
+// Returns the definition of the name in the scope, or nil if there is none.
+// Lazy arguments are evaluated when they are first looked up,
+// so their errors are returned here and thunks go no further.
+func lookup(scope fnScope, name string) (fnScope, error) {
+	value := scope.Definitions()[name]
+	if lazyValue, ok := value.(*thunk); ok {
+		return lazyValue.force()
+	}
+
+	return value, nil
+}
+
 func execBlock(expr BlockExpression, scope fnScope) EvalResult {
 	newBlock := Scope{
 		parent:      &scope,
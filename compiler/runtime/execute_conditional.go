@@ -2,15 +2,22 @@ package runtime
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
 	. "github.com/jonnyarnold/fn-go/compiler/parser"
 )
 
-// Converts a FunctionPrototypeExression into a runtime function.
+// Executes the first branch of the conditional whose condition is true.
 func execConditional(expr ConditionalExpression, scope fnScope) EvalResult {
+	if expr.Subject != nil {
+		return execMatch(expr, scope)
+	}
+
 	for _, branch := range expr.Branches {
-		result := execBranch(branch, scope)
+		result, matched := execBranch(branch, scope)
 
-		if result.Error != nil || result.Value != nil {
+		if result.Error != nil || matched {
 			return result
 		}
 	}
@@ -18,17 +25,141 @@ func execConditional(expr ConditionalExpression, scope fnScope) EvalResult {
 	return EvalResult{Error: errors.New("End of when{} reached without matching branch!")}
 }
 
-func execBranch(expr ConditionalBranchExpression, scope fnScope) EvalResult {
+// Executes the branch if its condition is true.
+// Returns true if the branch was matched, regardless of the value of its block.
+func execBranch(expr ConditionalBranchExpression, scope fnScope) (EvalResult, bool) {
 	conditionResult := exec(expr.Condition, scope)
 
 	if conditionResult.Error != nil {
-		return conditionResult
+		return conditionResult, false
 	}
 
 	// If the condition is true, execute the block
 	if AsBool(conditionResult.Value) {
-		return ExecuteIn(expr.Body.Body, scope)
+		return execMatchedBranch(expr, scope), true
+	}
+
+	return EvalResult{}, false
+}
+
+// Executes the first branch of the conditional whose pattern matches the subject.
+// Fields named by the pattern are defined for the branch.
+//
+// Unless there is a `true` branch, every variant of the subject's union must be handled.
+func execMatch(expr ConditionalExpression, scope fnScope) EvalResult {
+	subjectResult := exec(expr.Subject, scope)
+	if subjectResult.Error != nil {
+		return subjectResult
+	}
+
+	subject := subjectResult.Value
+	if err := checkExhaustive(expr, subject); err != nil {
+		return EvalResult{Error: err}
+	}
+
+	for _, branch := range expr.Branches {
+		if _, isCatchAll := branch.Condition.(BooleanExpression); isCatchAll {
+			return execMatchedBranch(branch, scope)
+		}
+
+		pattern := branch.Condition.(VariantPatternExpression)
+		branchScope, matched, err := matchVariant(pattern, subject, scope)
+		if err != nil {
+			return EvalResult{Error: err}
+		}
+
+		if matched {
+			return execMatchedBranch(branch, branchScope)
+		}
+	}
+
+	return EvalResult{Error: errors.New(fmt.Sprintf("End of when reached without matching %s!", subject.String()))}
+}
+
+// Executes the body of a matched branch.
+func execMatchedBranch(branch ConditionalBranchExpression, scope fnScope) EvalResult {
+	result := ExecuteIn(branch.Body.Body, scope)
+	if result.Error == nil && result.Value == nil {
+		result.Value = Nothing
+	}
+
+	return result
+}
+
+// Matches the subject against the variant pattern.
+// If it matches, returns a scope with the fields of the pattern defined.
+func matchVariant(pattern VariantPatternExpression, subject fnScope, scope fnScope) (fnScope, bool, error) {
+	value, err := lookup(scope, pattern.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var variantType recordType
+	switch variant := value.(type) {
+	case recordType:
+		variantType = variant
+	case record:
+		variantType = variant.recordType
+	case nil:
+		return nil, false, errors.New(fmt.Sprintf("%s is not defined.", pattern.Name))
+	default:
+		return nil, false, errors.New(fmt.Sprintf("%s is not a variant or record type", pattern.Name))
+	}
+
+	if pattern.Fields != nil && len(pattern.Fields) != len(variantType.Fields) {
+		return nil, false, errors.New(fmt.Sprintf(
+			"Cannot match %s: %s has %d fields",
+			pattern.String(),
+			variantType.Name,
+			len(variantType.Fields),
+		))
+	}
+
+	rec, ok := subject.(record)
+	if !ok || rec.recordType.id != variantType.id {
+		return nil, false, nil
+	}
+
+	var branchScope fnScope = Scope{parent: &scope, definitions: defMap{}}
+	for idx, field := range pattern.Fields {
+		var err error
+		branchScope, err = bindPattern(field, rec.values[variantType.Fields[idx]], branchScope)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return branchScope, true, nil
+}
+
+// Returns an error if the subject is a variant of a union
+// and the conditional does not handle every variant.
+func checkExhaustive(expr ConditionalExpression, subject fnScope) error {
+	rec, ok := subject.(record)
+	if !ok || rec.recordType.Variants == nil {
+		return nil
+	}
+
+	handled := map[string]bool{}
+	for _, branch := range expr.Branches {
+		switch branch.Condition.(type) {
+		case BooleanExpression:
+			return nil
+		case VariantPatternExpression:
+			handled[branch.Condition.(VariantPatternExpression).Name] = true
+		}
+	}
+
+	missing := []string{}
+	for _, name := range rec.recordType.Variants {
+		if !handled[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New(fmt.Sprintf("when %s does not handle %s", expr.Subject.String(), strings.Join(missing, ", ")))
 	}
 
-	return EvalResult{Value: nil}
+	return nil
 }
@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// Execute an extend expression.
+// Returns a new block whose attribute lookup falls back to the base block.
+// Within the body, `super` refers to the base block.
+func execExtend(expr ExtendExpression, scope fnScope) EvalResult {
+	execBase := exec(expr.Base, scope)
+	if execBase.Error != nil {
+		return execBase
+	}
+
+	baseBlock, ok := execBase.Value.(Scope)
+	if !ok {
+		return EvalResult{Error: errors.New(fmt.Sprintf("extend can only be used on blocks, got %s", execBase.Value.String()))}
+	}
+
+	// super is defined between the block and its parent, so it is not an
+	// attribute of the block: destructuring, with and JSON do not see it.
+	// Like any name around a block, `B.super` still finds it.
+	var superScope fnScope = Scope{
+		parent:      &scope,
+		definitions: defMap{"super": baseBlock},
+	}
+
+	newBlock := Scope{
+		parent:      &superScope,
+		definitions: defMap{},
+		proto:       &baseBlock,
+	}
+
+	result := ExecuteIn(expr.Body.Body, newBlock)
+	if result.Error != nil {
+		return result
+	}
+
+	return EvalResult{Value: newBlock, Scope: scope}
+}
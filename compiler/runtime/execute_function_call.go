@@ -14,22 +14,47 @@ func execFunctionCall(expr FunctionCallExpression, scope fnScope) EvalResult {
 	// Special cases
 	switch id {
 	case "=":
-		return execDefinition(args[0].(IdentifierExpression), args[1], scope)
+		return execDefinition(args[0], args[1], scope)
 	case ".":
 		return execDereference(args[0], args[1], scope)
+	case "with":
+		return execWith(args[0], args[1], scope)
+	case "and":
+		return execAnd(args, scope)
+	case "or":
+		return execOr(args, scope)
+	case "Union":
+		return execUnion(args, scope)
 	case "import!":
 		return execInternalImport(args[0].(StringExpression), scope)
 	case "import":
 		return execVariableImport(args[0].(StringExpression), scope)
 	}
 
-	fnToCall := scope.Definitions()[id]
+	// Operators always dispatch on their first argument,
+	// even within blocks that define them.
+	for _, operator := range OverloadableOperators {
+		if id == operator {
+			return execCall(id, args, topScope, scope)
+		}
+	}
+
+	return execCall(id, args, scope, scope)
+}
+
+// Calls the function with the given id in the scope,
+// executing the arguments in argScope.
+func execCall(id string, args []Expression, scope fnScope, argScope fnScope) EvalResult {
+	fnToCall, err := lookup(scope, id)
+	if err != nil {
+		return EvalResult{Error: err}
+	}
+
 	if fnToCall == nil {
 		return EvalResult{Error: errors.New(fmt.Sprintf("%s is not a defined function on:\n%s", id, scope.String()))}
 	}
 
-	// TODO: Lazy evaluation?
-	evalArgs, err := execArgs(args, scope)
+	evalArgs, err := execArgs(args, argScope, fnToCall)
 	if err != nil {
 		return EvalResult{Error: err}
 	}
@@ -39,32 +64,76 @@ func execFunctionCall(expr FunctionCallExpression, scope fnScope) EvalResult {
 		return EvalResult{Error: err}
 	}
 
-	return EvalResult{Value: value, Scope: scope}
+	// Functions always return a value; an empty body returns nil.
+	if value == nil {
+		value = Nothing
+	}
+
+	return EvalResult{Value: value, Scope: argScope}
 }
 
-func execArgs(args []Expression, scope fnScope) ([]fnScope, error) {
-	// TODO: Lazy evaluation?
+// Executes the arguments of a function call.
+// Arguments the callee declares as lazy are not executed, but wrapped in a thunk.
+func execArgs(args []Expression, scope fnScope, callee fnScope) ([]fnScope, error) {
+	function, _ := callee.(functionScope)
+
 	evalArgs := []fnScope{}
-	for _, arg := range args {
-		result := exec(arg, scope)
-		if result.Error != nil {
-			return nil, result.Error
+	for idx, arg := range args {
+		named, isNamed := arg.(NamedArgumentExpression)
+
+		var value fnScope
+		if isNamed && function.isLazy(named.Name) {
+			value = newThunk(named.Value, scope)
+		} else if isNamed {
+			result := exec(named.Value, scope)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+
+			value = result.Value
+		} else if idx < len(function.ArgumentNames) && function.isLazy(function.ArgumentNames[idx]) {
+			value = newThunk(arg, scope)
+		} else {
+			result := exec(arg, scope)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+
+			value = result.Value
+		}
+
+		if isNamed {
+			value = namedArgument{name: named.Name, value: value}
 		}
 
-		evalArgs = append(evalArgs, result.Value)
+		evalArgs = append(evalArgs, value)
 	}
 
 	return evalArgs, nil
 }
 
 // Execute a `=` function call.
-func execDefinition(id IdentifierExpression, value Expression, scope fnScope) EvalResult {
+// The left-hand side may be an identifier or a destructuring pattern.
+func execDefinition(pattern Expression, value Expression, scope fnScope) EvalResult {
 	execValue := exec(value, scope)
 	if execValue.Error != nil {
 		return execValue
 	}
 
-	newScope, err := scope.Define(id.Name, execValue.Value)
+	// Functions take the name they are first defined with,
+	// so errors can refer to them.
+	definedValue := execValue.Value
+	if typed, ok := pattern.(TypedPatternExpression); ok {
+		pattern = typed.Pattern
+	}
+
+	if id, ok := pattern.(IdentifierExpression); ok {
+		if function, ok := definedValue.(functionScope); ok {
+			definedValue = function.named(id.Name)
+		}
+	}
+
+	newScope, err := bindPattern(pattern, definedValue, scope)
 	if err != nil {
 		return EvalResult{Error: err}
 	}
@@ -82,10 +151,44 @@ func execDereference(parent Expression, child Expression, scope fnScope) EvalRes
 		return execParent
 	}
 
-	result := exec(child, execParent.Value)
+	result := dereference(execParent.Value, parent, child, scope)
 	return EvalResult{
 		Value: result.Value,
 		Scope: scope,
 		Error: result.Error,
 	}
 }
+
+// Executes the child of a `.` in the parent's value.
+// Chains such as `x.foo(a).bar` are nested in the child,
+// and every call in them takes its arguments from the calling scope.
+func dereference(value fnScope, parent Expression, child Expression, scope fnScope) EvalResult {
+	call, ok := child.(FunctionCallExpression)
+	if !ok {
+		return exec(child, value)
+	}
+
+	switch {
+	case call.Identifier.Name == ".":
+		inner := dereference(value, parent, call.Arguments[0], scope)
+		if inner.Error != nil {
+			return inner
+		}
+
+		return dereference(inner.Value, call.Arguments[0], call.Arguments[1], scope)
+
+	// In strict mode, blocks cannot be changed once they are created.
+	case call.Identifier.Name == "=" && Strict:
+		return EvalResult{Error: errors.New(fmt.Sprintf(
+			"Cannot define %s on %s in strict mode; use `%s with { ... }` instead",
+			call.Arguments[0].String(),
+			parent.String(),
+			parent.String(),
+		))}
+
+	case !IsOperator(call.Identifier.Name):
+		return execCall(call.Identifier.Name, call.Arguments, value, scope)
+	}
+
+	return exec(child, value)
+}
@@ -1,35 +1,56 @@
 package runtime
 
 import (
-	"errors"
-	"fmt"
 	. "github.com/jonnyarnold/fn-go/compiler/parser"
 )
 
 // Converts a FunctionPrototypeExression into a runtime function.
 func execFunctionPrototype(expr FunctionPrototypeExpression, scope fnScope) EvalResult {
 	var argNames []string
+	defaults := defMap{}
+	lazy := map[string]bool{}
+	restName := ""
+
 	for _, argExpr := range expr.Arguments {
-		argNames = append(argNames, argExpr.Name)
-	}
+		name := argExpr.Pattern.String()
+		if argExpr.Rest {
+			restName = name
+			continue
+		}
 
-	innerScope := Scope{
-		parent:      &scope,
-		definitions: defMap{},
+		argNames = append(argNames, name)
+
+		if argExpr.Lazy {
+			lazy[name] = true
+		}
+
+		// Defaults are evaluated once, when the function is defined.
+		if argExpr.Default != nil {
+			result := exec(argExpr.Default, scope)
+			if result.Error != nil {
+				return result
+			}
+
+			defaults[name] = result.Value
+		}
 	}
 
 	value := fn(argNames, func(argValues []fnScope) (fnScope, error) {
-		if len(argValues) != len(argNames) {
-			return nil, errors.New(fmt.Sprintf(
-				"Argument number mismatch: got %i, need %i",
-				len(argValues),
-				len(argNames),
-			))
+		// Each call gets its own scope, so arguments from
+		// previous (or recursive) calls are not visible.
+		var innerScope fnScope = Scope{
+			parent:      &scope,
+			definitions: defMap{},
 		}
 
-		// Assign args to the scope
-		for idx, name := range argNames {
-			innerScope.definitions[name] = argValues[idx]
+		// Assign args to the scope.
+		// The rest argument is always last, so the indexes line up.
+		var err error
+		for idx, argExpr := range expr.Arguments {
+			innerScope, err = bindPattern(argExpr.Pattern, argValues[idx], innerScope)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		// Evaluate the function!
@@ -41,5 +62,9 @@ func execFunctionPrototype(expr FunctionPrototypeExpression, scope fnScope) Eval
 		return result.Value, nil
 	})
 
+	value.Defaults = defaults
+	value.RestName = restName
+	value.Lazy = lazy
+
 	return EvalResult{Value: value, Scope: scope}
 }
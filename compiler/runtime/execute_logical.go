@@ -0,0 +1,62 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// Execute an `and` function call.
+// The second argument is not evaluated if the first is false.
+func execAnd(args []Expression, scope fnScope) EvalResult {
+	return execLogical("and", andValues, args, scope)
+}
+
+// Execute an `or` function call.
+// The second argument is not evaluated if the first is true.
+func execOr(args []Expression, scope fnScope) EvalResult {
+	return execLogical("or", orValues, args, scope)
+}
+
+// Executes the first argument, then combines it with the second,
+// which is only executed if combine asks for it.
+func execLogical(id string, combine func(fnScope, func() (fnScope, error)) (fnScope, error), args []Expression, scope fnScope) EvalResult {
+	if len(args) != 2 {
+		return EvalResult{Error: errors.New(fmt.Sprintf("Argument number mismatch calling %s: got %d, need 2", id, len(args)))}
+	}
+
+	lhs := exec(args[0], scope)
+	if lhs.Error != nil {
+		return lhs
+	}
+
+	value, err := combine(lhs.Value, func() (fnScope, error) {
+		rhs := exec(args[1], scope)
+		return rhs.Value, rhs.Error
+	})
+	if err != nil {
+		return EvalResult{Error: err}
+	}
+
+	return EvalResult{Value: value, Scope: scope}
+}
+
+// Returns lhs if it is false, or rhs otherwise.
+// rhs is only called if lhs is true.
+func andValues(lhs fnScope, rhs func() (fnScope, error)) (fnScope, error) {
+	if !AsBool(lhs) {
+		return lhs, nil
+	}
+
+	return rhs()
+}
+
+// Returns lhs if it is true, or rhs otherwise.
+// rhs is only called if lhs is not true.
+func orValues(lhs fnScope, rhs func() (fnScope, error)) (fnScope, error) {
+	if AsBool(lhs) {
+		return lhs, nil
+	}
+
+	return rhs()
+}
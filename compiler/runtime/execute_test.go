@@ -1,9 +1,15 @@
 package runtime
 
 import (
+	"bufio"
+	"bytes"
 	. "github.com/jonnyarnold/fn-go/compiler/parser"
 	"github.com/jonnyarnold/fn-go/compiler/tokeniser"
 	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -23,6 +29,16 @@ func eval(code string) EvalResult {
 	return Execute(exprsFor(code))
 }
 
+// Returns what the code writes to stdout.
+func output(code string) string {
+	var buffer bytes.Buffer
+	stdout = &buffer
+	defer func() { stdout = os.Stdout }()
+
+	eval(code)
+	return buffer.String()
+}
+
 func TestExecute(t *testing.T) {
 	Convey("An empty expression list", t, func() {
 
@@ -156,319 +172,1017 @@ func TestExecute(t *testing.T) {
 			So(result.Error, ShouldNotBeNil)
 		})
 
-	})
+		Convey("can be extended from outside", func() {
+			result := eval("x = { }; x.foo = 1; x.foo")
 
-	Convey("Function call expressions", t, func() {
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
 
-		Convey("return an error if not defined", func() {
-			result := eval("notDefined()")
+		Convey("cannot be extended from outside in strict mode", func() {
+			Strict = true
+			defer func() { Strict = false }()
 
+			result := eval("x = { }; x.foo = 1")
 			So(result.Error, ShouldNotBeNil)
 		})
 
-		Convey("returns the function value", func() {
-			result := eval("returnX = (x) { x }; returnX(1)")
+		Convey("list their own names, sorted", func() {
+			result := eval("{ b = 1; a = 2 }")
+			So(result.Value.(Scope).Names(), ShouldResemble, []string{"a", "b"})
+		})
+
+	})
+
+	Convey("with", t, func() {
+
+		Convey("returns a new block with the definitions added", func() {
+			result := eval("x = { foo = 1 }; y = x with { bar = 2 }; y.foo + y.bar")
 
-			So(result.Value, ShouldResemble, number{value: "1"})
 			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "3"})
 		})
 
-		Convey("executes arguments", func() {
-			result := eval("returnX = (x) { x }; returnX(2 + 2)")
+		Convey("replaces existing definitions", func() {
+			result := eval("x = { foo = 1 }; y = x with { foo = 2 }; y.foo")
 
 			So(result.Error, ShouldBeNil)
-			So(result.Value, ShouldResemble, number{value: "4"})
+			So(result.Value, ShouldResemble, number{value: "2"})
 		})
 
-		Convey("=", func() {
-
-			Convey("sets the given ID to the given value", func() {
-				result := eval("x = 2; x")
+		Convey("does not change the original block", func() {
+			result := eval("x = { foo = 1 }; y = x with { foo = 2; bar = 3 }; x")
 
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "2"})
+			So(result.Error, ShouldBeNil)
+			So(result.Value.(Scope).definitions, ShouldResemble, defMap{
+				"foo": number{value: "1"},
 			})
+		})
 
-			Convey("returns the defining block", func() {
-				result := eval("foo = \"bar\"")
+		Convey("keeps functions using the names of the original block", func() {
+			result := eval("b = { x = 1; f = () { x } }; c = b with { x = 2 }; c.f()")
 
-				So(result.Error, ShouldBeNil)
-				So(result.Value.(Scope).definitions, ShouldResemble, defMap{
-					"foo": fnString{value: "bar"},
-				})
-			})
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
 
-			Convey("returns an error if ID already defined", func() {
-				result := eval("x = 1; x = 2")
+		Convey("returns an error if used on something other than a block", func() {
+			result := eval("1 with { foo = 2 }")
+			So(result.Error, ShouldNotBeNil)
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+	})
+
+	Convey("extend", t, func() {
 
+		Convey("falls back to the base block for attributes", func() {
+			result := eval("base = { foo = 1 }; child = extend(base) { bar = 2 }; child.foo + child.bar")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "3"})
 		})
 
-		Convey(".", func() {
+		Convey("allows attributes of the base block to be overridden", func() {
+			result := eval("base = { foo = 1 }; child = extend(base) { foo = 2 }; List(base.foo, child.foo)")
 
-			Convey("executes the child in the parent scope", func() {
-				result := eval("x = { print = (x) { x } }; x.print(1)")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{number{value: "1"}, number{value: "2"}}})
+		})
 
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
-			})
+		Convey("defines super as the base block", func() {
+			result := eval(`base = { next = (x) { x + 1 } };
+				child = extend(base) { next = (x) { (super.next(x)) * 2 } };
+				child.next(1)`)
 
-			Convey("returns an error if the parent scope is not defined", func() {
-				result := eval("x.print(1)")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "4"})
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("does not make super an attribute", func() {
+			result := eval("base = { }; child = extend(base) { }; { super } = child")
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("finds super through the block, like other names around it", func() {
+			result := eval("base = { x = 1 }; child = extend(base) { }; child.super.x")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
 		})
 
-		Convey("import!", func() {
+		Convey("prefers attributes of the base block to the enclosing scope", func() {
+			result := eval("foo = 1; base = { foo = 2 }; child = extend(base) { bar = foo }; child.bar")
 
-			Convey("includes the file content directly into the current scope", func() {
-				result := eval("import!(\"test_import.fn\"); x")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "2"})
+		})
 
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
-			})
+		Convey("can be chained", func() {
+			result := eval("a = { foo = 1 }; b = extend(a) { }; c = extend(b) { }; c.foo")
 
-			Convey("returns an error if the file does not exist", func() {
-				result := eval("import!(\"DOES-NOT-EXIST\")")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("is kept by with", func() {
+			result := eval("base = { foo = 1 }; child = extend(base) { }; (child with { bar = 2 }).foo")
 
-			Convey("returns an error if variables are already defined", func() {
-				result := eval("x = 1; import!(\"test_import.fn\")")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("returns an error if the base is not a block", func() {
+			result := eval("extend(1) { }")
+			So(result.Error, ShouldNotBeNil)
+		})
+
+	})
 
+	Convey("Operator overloading", t, func() {
+		vector := "Vector = (x, y) { { x = x; y = y; + = (other) { Vector(x + other.x, y + other.y) } } }; "
+
+		Convey("calls the operator defined by the block", func() {
+			result := eval(vector + "v = Vector(1, 2) + Vector(3, 4); v.x")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "4"})
 		})
 
-		Convey("import", func() {
+		Convey("returns an error if the block does not define the operator", func() {
+			result := eval(vector + "Vector(1, 2) * 2")
 
-			Convey("includes the file content in the variable", func() {
-				result := eval("a = import(\"test_import.fn\"); a.x")
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldContainSubstring, "does not implement *")
+		})
 
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
-			})
+		Convey("returns an error for lists", func() {
+			result := eval("List(1) + 1")
+			So(result.Error, ShouldNotBeNil)
+		})
 
-			Convey("returns an error if the file does not exist", func() {
-				result := eval("a = import(\"DOES-NOT-EXIST\")")
+		Convey("does not find operators in the enclosing scope", func() {
+			result := eval("x = { }; x - 1")
+			So(result.Error, ShouldNotBeNil)
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("finds operators on the blocks a block extends", func() {
+			result := eval("base = { - = (other) { 10 - other } }; child = extend(base) { }; child - 1")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "9"})
 		})
 
-		Convey("for user-defined functions", func() {
+		Convey("uses eq if the block defines it", func() {
+			result := eval("a = { id = 1; eq = (other) { id eq other.id } }; b = { id = 1; name = \"b\" }; a eq b")
 
-			Convey("return an error on mismatched argument lengths", func() {
-				result := eval("x = (a) { a }; x(1, 2)")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, fnBool{value: true})
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("uses lessThan for lessThan and moreThan", func() {
+			result := eval("Size = (n) { { n = n; lessThan = (other) { n lessThan other.n } } }; List(Size(1) lessThan Size(2), Size(1) moreThan Size(2))")
 
-			Convey("return an error on argument errors", func() {
-				result := eval("x = (a) { a }; x(foo)")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{fnBool{value: true}, fnBool{value: false}}})
+		})
 
-				So(result.Error, ShouldNotBeNil)
-			})
+		Convey("uses asString to convert the block to a string", func() {
+			result := eval("x = { asString = () { \"an x\" } }; String(x)")
 
-			Convey("execute in the function prototype scope", nil) // func() {
-			// result := eval("x = (a) { print = a; print }; x(1)")
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, fnString{value: "an x"})
+		})
 
-			// So(result.Error, ShouldBeNil)
-			// So(result.Value, ShouldResemble, number{value: "1"})
-			// })
+		Convey("uses call when the block is called", func() {
+			result := eval("base = { call = (n) { n * 2 } }; child = extend(base) { }; child(3)")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "6"})
 		})
 
 	})
 
-	Convey("Conditional expressions", t, func() {
+	Convey("Records", t, func() {
+		point := "Point = Record(\"Point\", List(\"x\", \"y\")); "
 
-		Convey("return the block's value if the condition is true", func() {
-			result := eval("when { true { 1 } }")
+		Convey("are constructed with positional arguments", func() {
+			result := eval(point + "p = Point(1, 2); (p.x) + (p.y)")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "3"})
+		})
+
+		Convey("are constructed with named arguments", func() {
+			result := eval(point + "p = Point(y: 2, x: 1); p.x")
 
 			So(result.Error, ShouldBeNil)
 			So(result.Value, ShouldResemble, number{value: "1"})
 		})
 
-		Convey("return an error if no conditions are met", func() {
-			result := eval("when { false { 1 } }")
+		Convey("return an error when constructed with too many arguments", func() {
+			result := eval(point + "Point(1, 2, 3)")
+			So(result.Error, ShouldNotBeNil)
+		})
 
+		Convey("return an error when constructed with an unknown field", func() {
+			result := eval(point + "Point(1, z: 2)")
 			So(result.Error, ShouldNotBeNil)
 		})
 
-		Convey("return an error if conditions contain errors", func() {
-			result := eval("when { foo { 1 } }")
+		Convey("cannot be changed", func() {
+			result := eval(point + "p = Point(1, 2); p.x = 3")
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("can be copied with changed fields using with", func() {
+			result := eval(point + "p = Point(1, 2); q = p with { x = 3 }; List(p.x, q.x)")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{number{value: "1"}, number{value: "3"}}})
+		})
 
+		Convey("cannot gain fields using with", func() {
+			result := eval(point + "p = Point(1, 2); p with { z = 3 }")
 			So(result.Error, ShouldNotBeNil)
 		})
 
-		Convey("stops executing conditions on the first true condition", func() {
-			result := eval("when { true { 1 } true { 2 } }")
+		Convey("have a generated asString", func() {
+			result := eval(point + "String(Point(1, 2))")
 
 			So(result.Error, ShouldBeNil)
-			So(result.Value, ShouldResemble, number{value: "1"})
+			So(result.Value, ShouldResemble, fnString{value: "Point(x: 1, y: 2)"})
+		})
+
+		Convey("are equal if their fields are equal", func() {
+			result := eval(point + "List(Point(1, 2) eq Point(1, 2), Point(1, 2) eq Point(1, 3))")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{fnBool{value: true}, fnBool{value: false}}})
+		})
+
+		Convey("are not equal to records of another type", func() {
+			result := eval(point + "Size = Record(\"Size\", List(\"x\", \"y\")); Point(1, 2) eq Size(1, 2)")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, fnBool{value: false})
+		})
+
+		Convey("can be checked with is", func() {
+			result := eval(point + "List(Point.is(Point(1, 2)), Point.is({ x = 1; y = 2 }))")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{fnBool{value: true}, fnBool{value: false}}})
+		})
+
+		Convey("return an error if a field name is repeated", func() {
+			result := eval("Record(\"Point\", List(\"x\", \"x\"))")
+			So(result.Error, ShouldNotBeNil)
 		})
 
 	})
 
-	Convey("Built-in functions", t, func() {
+	Convey("Unions", t, func() {
+		shape := "Shape = Union(Circle(r), Rect(w, h), Empty); "
 
-		Convey("are available from the expression list", func() {
-			result := eval("print")
+		Convey("define a constructor for each variant", func() {
+			result := eval(shape + "c = Circle(2); c.r")
 
 			So(result.Error, ShouldBeNil)
-			So(result.Value, ShouldHaveSameTypeAs, functionScope{})
+			So(result.Value, ShouldResemble, number{value: "2"})
 		})
 
-		Convey("are available within user-defined functions", func() {
-			result := eval("x = (a) { print(a) }; x(1)")
+		Convey("define variants as attributes", func() {
+			result := eval(shape + "c = Shape.Circle(2); Shape.is(c)")
 
 			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, fnBool{value: true})
 		})
 
-		Convey("cannot be extended", func() {
-			result := eval("print.foo = 1")
+		Convey("define variants without fields as values", func() {
+			result := eval(shape + "String(Empty)")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, fnString{value: "Empty"})
+		})
+
+		Convey("return an error if a variant is repeated", func() {
+			result := eval("Union(Circle(r), Circle(d))")
 			So(result.Error, ShouldNotBeNil)
 		})
 
-		Convey("are available within user-defined blocks", func() {
-			result := eval("x = { foo = print }; x.foo(1)")
+		Convey("are matched by when", func() {
+			area := "area = (s) { when s { Circle(r) { r * r * 3 } Rect(w, h) { w * h } Empty { 0 } } }; "
+			result := eval(shape + area + "List(area(Circle(1)), area(Rect(2, 3)), area(Empty))")
 
 			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, list{Items: []fnScope{number{value: "3"}, number{value: "6"}, number{value: "0"}}})
 		})
 
-		Convey("are available within conditions", func() {
-			result := eval("when { true eq true { 1 } }")
+		Convey("can be matched by name without fields", func() {
+			result := eval(shape + "when Rect(1, 2) { Circle { 1 } Rect { 2 } Empty { 3 } }")
 
 			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "2"})
 		})
 
-		Convey("+", func() {
+		Convey("return an error if when does not handle every variant", func() {
+			result := eval(shape + "when Circle(1) { Circle(r) { r } }")
 
-			Convey("sums two integers", func() {
-				result := eval("2 + 2")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "4"})
-			})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldContainSubstring, "Rect, Empty")
+		})
 
-			Convey("sums two floats", func() {
-				result := eval("2.5 + 2.5")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "5"})
-			})
+		Convey("can be matched with a true branch for the remaining variants", func() {
+			result := eval(shape + "when Empty { Circle(r) { r } true { 0 } }")
 
-			Convey("sums an integer and a float", func() {
-				result := eval("2.5 + 2")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "4.5"})
-			})
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "0"})
+		})
 
+		Convey("return an error if a pattern has the wrong number of fields", func() {
+			result := eval(shape + "when Circle(1) { Circle(a, b) { a } true { 0 } }")
+			So(result.Error, ShouldNotBeNil)
 		})
 
-		Convey("-", func() {
+		Convey("do not define pattern fields outside the branch", func() {
+			result := eval(shape + "when Circle(1) { Circle(r) { r } true { 0 } }; r")
+			So(result.Error, ShouldNotBeNil)
+		})
 
-			Convey("takes the difference of two integers", func() {
-				result := eval("4 - 3")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
-			})
+	})
 
-			Convey("takes the difference of two floats", func() {
-				result := eval("4.5 - 3.5")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
-			})
+	Convey("Type annotations", t, func() {
 
-			Convey("takes the difference of an integer and a float", func() {
-				result := eval("4.5 - 3")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1.5"})
-			})
+		Convey("are ignored when running", func() {
+			result := eval("add = (a: Number, b: Number) -> Number { a + b }; x: Number = 2; add(x, 3)")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "5"})
 		})
 
-		Convey("*", func() {
+	})
 
-			Convey("multiplies two integers", func() {
-				result := eval("2 * 2")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "4"})
-			})
+	Convey("Dereferenced function calls", t, func() {
 
-			Convey("multiplies two floats", func() {
-				result := eval("1.5 * 1.5")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "2.25"})
-			})
+		Convey("take their arguments from the calling scope", func() {
+			result := eval("x = { double = (n) { n * 2 } }; y = 3; x.double(y)")
 
-			Convey("multiplies an integer and a float", func() {
-				result := eval("2 * 1.5")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "3"})
-			})
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "6"})
+		})
+
+		Convey("take their arguments from the calling scope in a chain", func() {
+			result := eval("g = (n) { List(n) }; y = 3; JSON.stringify(g(y)).asString()")
 
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, FnString("[3]"))
 		})
 
-		Convey("/", func() {
+	})
 
-			Convey("divides two integers", func() {
+	Convey("Function call expressions", t, func() {
+
+		Convey("return an error if not defined", func() {
+			result := eval("notDefined()")
+
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("returns the function value", func() {
+			result := eval("returnX = (x) { x }; returnX(1)")
+
+			So(result.Value, ShouldResemble, number{value: "1"})
+			So(result.Error, ShouldBeNil)
+		})
+
+		Convey("executes arguments", func() {
+			result := eval("returnX = (x) { x }; returnX(2 + 2)")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "4"})
+		})
+
+		Convey("=", func() {
+
+			Convey("sets the given ID to the given value", func() {
+				result := eval("x = 2; x")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+			})
+
+			Convey("returns the defining block", func() {
+				result := eval("foo = \"bar\"")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value.(Scope).definitions, ShouldResemble, defMap{
+					"foo": fnString{value: "bar"},
+				})
+			})
+
+			Convey("returns an error if ID already defined", func() {
+				result := eval("x = 1; x = 2")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("replaces the definition in a redefinable scope", func() {
+				redefined := []string{}
+				scope := RedefinableScope(func(id string) { redefined = append(redefined, id) })
+				result := ExecuteIn(exprsFor("x = 1; f = () { x }; x = 2; f()"), scope)
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+				So(redefined, ShouldResemble, []string{"x"})
+			})
+
+			Convey("returns an error if ID already defined in a block of a redefinable scope", func() {
+				result := ExecuteIn(exprsFor("b = { x = 1; x = 2 }"), RedefinableScope(func(id string) {}))
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("destructures lists", func() {
+				result := eval("List(a, b) = List(1, 2); b")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+			})
+
+			Convey("destructures nested lists", func() {
+				result := eval("List(a, List(b, c)) = List(1, List(2, 3)); c")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("returns an error if a list has the wrong number of items", func() {
+				result := eval("List(a, b) = List(1, 2, 3)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error if a list pattern is given a non-list", func() {
+				result := eval("List(a, b) = 1")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("destructures blocks", func() {
+				result := eval("{ foo, bar } = { foo = 1; bar = 2 }; foo + bar")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("returns an error if a block does not define a name", func() {
+				result := eval("{ foo, baz } = { foo = 1 }")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey(".", func() {
+
+			Convey("executes the child in the parent scope", func() {
+				result := eval("x = { print = (x) { x } }; x.print(1)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("returns an error if the parent scope is not defined", func() {
+				result := eval("x.print(1)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("import!", func() {
+
+			Convey("includes the file content directly into the current scope", func() {
+				result := eval("import!(\"test_import.fn\"); x")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("returns an error if the file does not exist", func() {
+				result := eval("import!(\"DOES-NOT-EXIST\")")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error if variables are already defined", func() {
+				result := eval("x = 1; import!(\"test_import.fn\")")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("import", func() {
+
+			Convey("includes the file content in the variable", func() {
+				result := eval("a = import(\"test_import.fn\"); a.x")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("returns an error if the file does not exist", func() {
+				result := eval("a = import(\"DOES-NOT-EXIST\")")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("for user-defined functions", func() {
+
+			Convey("return an error on mismatched argument lengths", func() {
+				result := eval("x = (a) { a }; x(1, 2)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("return an error on argument errors", func() {
+				result := eval("x = (a) { a }; x(foo)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("can be called more than once", func() {
+				result := eval("x = (a) { b = a; b }; x(1); x(2)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+			})
+
+			Convey("destructure list arguments", func() {
+				result := eval("first = (List(a, b)) { a }; first(List(1, 2))")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("destructure block arguments", func() {
+				result := eval("getFoo = ({ foo }) { foo }; getFoo({ foo = 1 })")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("use default values for missing arguments", func() {
+				result := eval("add = (a, b = 2) { a + b }; add(1)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("prefer given arguments to default values", func() {
+				result := eval("add = (a, b = 2) { a + b }; add(1, 5)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "6"})
+			})
+
+			Convey("bind extra arguments to the rest argument as a list", func() {
+				result := eval("f = (first, ...rest) { rest }; f(1, 2, 3)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{
+					Items: []fnScope{number{value: "2"}, number{value: "3"}},
+				})
+			})
+
+			Convey("bind an empty list to the rest argument if there are no extra arguments", func() {
+				result := eval("f = (first, ...rest) { rest }; f(1)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{}})
+			})
+
+			Convey("accept named arguments", func() {
+				result := eval("sub = (a, b = 2) { a - b }; sub(b: 1, a: 5)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+			Convey("return an error on unknown named arguments", func() {
+				result := eval("f = (a) { a }; f(b: 1)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("return an error if an argument is given twice", func() {
+				result := eval("f = (a) { a }; f(1, a: 1)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("do not evaluate lazy arguments that are not used", func() {
+				result := eval("choose = (c, lazy a, lazy b) { when { c { a } true { b } } }; choose(true, 1, notDefined)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("evaluate lazy arguments when they are used", func() {
+				result := eval("f = (lazy a) { a }; f(notDefined)")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("evaluate lazy arguments in the calling scope", func() {
+				result := eval("f = (lazy a) { x = 2; a }; x = 1; f(x + 1)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+			})
+
+			Convey("return the errors of lazy arguments that are called", func() {
+				result := eval("f = (lazy g) { g(1) }; f(exit(3))")
+				So(result.Error, ShouldResemble, ExitError{Code: 3})
+			})
+
+			Convey("keep the lazy arguments of lazy functions", func() {
+				result := eval("f = (lazy g) { g(exit(3)) }; f((lazy a) { 1 })")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("are partially applied when given too few arguments", func() {
+				result := eval("add = (a, b) { a + b }; addOne = add(1); addOne(2)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("can be partially applied by name", func() {
+				result := eval("sub = (a, b) { a - b }; minusOne = sub(b: 1); minusOne(5)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+			Convey("keep their defaults when partially applied", func() {
+				result := eval("f = (a, b, c = 10) { a + b + c }; g = f(1); List(g(2), g(2, 3))")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value.(list).Items, ShouldResemble, []fnScope{number{value: "13"}, number{value: "6"}})
+			})
+
+			Convey("name the function in argument errors", func() {
+				result := eval("myFunction = (a, b) { a }; myFunction(1, 2, 3)")
+
+				So(result.Error, ShouldNotBeNil)
+				So(result.Error.Error(), ShouldContainSubstring, "myFunction")
+			})
+
+			Convey("return an error if an argument cannot be destructured", func() {
+				result := eval("first = (List(a, b)) { a }; first(List(1))")
+
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("execute in the function prototype scope", nil) // func() {
+			// result := eval("x = (a) { print = a; print }; x(1)")
+
+			// So(result.Error, ShouldBeNil)
+			// So(result.Value, ShouldResemble, number{value: "1"})
+			// })
+
+		})
+
+	})
+
+	Convey("Conditional expressions", t, func() {
+
+		Convey("return the block's value if the condition is true", func() {
+			result := eval("when { true { 1 } }")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
+
+		Convey("return an error if no conditions are met", func() {
+			result := eval("when { false { 1 } }")
+
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("return an error if conditions contain errors", func() {
+			result := eval("when { foo { 1 } }")
+
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("stops executing conditions on the first true condition", func() {
+			result := eval("when { true { 1 } true { 2 } }")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, number{value: "1"})
+		})
+
+		Convey("stop on a true condition whose block returns nil", func() {
+			result := eval("when { true { print(1) } true { 2 } }")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, Nothing)
+		})
+
+		Convey("return nil for an empty block", func() {
+			result := eval("when { true { } true { 2 } }")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldResemble, Nothing)
+		})
+
+	})
+
+	Convey("ToJSON", t, func() {
+
+		Convey("converts values to JSON", func() {
+			json, err := ToJSON(eval("{ a = List(1.50, \"x\", true, nil); b = { c = 1 } }").Value, "")
+
+			So(err, ShouldBeNil)
+			So(json, ShouldEqual, `{"a":[1.50,"x",true,null],"b":{"c":1}}`)
+		})
+
+		Convey("converts records to objects of their fields", func() {
+			json, _ := ToJSON(eval("Point = Record(\"Point\", List(\"x\", \"y\")); Point(1, 2)").Value, "")
+			So(json, ShouldEqual, `{"x":1,"y":2}`)
+		})
+
+		Convey("returns an error for functions", func() {
+			_, err := ToJSON(eval("(x) { x }").Value, "")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("returns an error for blocks that contain themselves", func() {
+			_, err := ToJSON(eval("b = { }; b.self = b; b").Value, "")
+			So(err, ShouldNotBeNil)
+		})
+
+	})
+
+	Convey("Built-in functions", t, func() {
+
+		Convey("are available from the expression list", func() {
+			result := eval("print")
+
+			So(result.Error, ShouldBeNil)
+			So(result.Value, ShouldHaveSameTypeAs, functionScope{})
+		})
+
+		Convey("are available within user-defined functions", func() {
+			result := eval("x = (a) { print(a) }; x(1)")
+
+			So(result.Error, ShouldBeNil)
+		})
+
+		Convey("cannot be extended", func() {
+			result := eval("print.foo = 1")
+
+			So(result.Error, ShouldNotBeNil)
+		})
+
+		Convey("are available within user-defined blocks", func() {
+			result := eval("x = { foo = print }; x.foo(1)")
+
+			So(result.Error, ShouldBeNil)
+		})
+
+		Convey("are available within conditions", func() {
+			result := eval("when { true eq true { 1 } }")
+
+			So(result.Error, ShouldBeNil)
+		})
+
+		Convey("+", func() {
+
+			Convey("sums two integers", func() {
+				result := eval("2 + 2")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+			Convey("sums two floats", func() {
+				result := eval("2.5 + 2.5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "5"})
+			})
+
+			Convey("sums an integer and a float", func() {
+				result := eval("2.5 + 2")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4.5"})
+			})
+
+			Convey("returns an error if the other value is not a number", func() {
+				result := eval("1 + \"a\"")
+				So(result.Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("-", func() {
+
+			Convey("takes the difference of two integers", func() {
+				result := eval("4 - 3")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("takes the difference of two floats", func() {
+				result := eval("4.5 - 3.5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("takes the difference of an integer and a float", func() {
+				result := eval("4.5 - 3")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1.5"})
+			})
+
+		})
+
+		Convey("*", func() {
+
+			Convey("multiplies two integers", func() {
+				result := eval("2 * 2")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+			Convey("multiplies two floats", func() {
+				result := eval("1.5 * 1.5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2.25"})
+			})
+
+			Convey("multiplies an integer and a float", func() {
+				result := eval("2 * 1.5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+		})
+
+		Convey("/", func() {
+
+			Convey("divides two integers", func() {
 				result := eval("4 / 2")
 				So(result.Error, ShouldBeNil)
 				So(result.Value, ShouldResemble, number{value: "2"})
 			})
 
-			Convey("divides two floats", func() {
-				result := eval("2.5 / 2.5")
-				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "1"})
+			Convey("divides two floats", func() {
+				result := eval("2.5 / 2.5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "1"})
+			})
+
+			Convey("divides an integer and a float", func() {
+				result := eval("2.5 / 5")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "0.5"})
+			})
+
+		})
+
+		Convey("lessThan and moreThan", func() {
+
+			Convey("compare numbers", func() {
+				result := eval("List(1 lessThan 2, 1 moreThan 2)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{fnBool{value: true}, fnBool{value: false}}})
+			})
+
+			Convey("return an error if the other value is not a number", func() {
+				So(eval("1 lessThan \"a\"").Error, ShouldNotBeNil)
+				So(eval("1 moreThan \"a\"").Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("and", func() {
+
+			Convey("returns true with two true boolean values", func() {
+				result := eval("true and true")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false if one value is false", func() {
+				result := eval("false and true")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns the last value if both are true", func() {
+				result := eval("1 and \"two\"")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnString{value: "two"})
+			})
+
+			Convey("does not evaluate the second value if the first is false", func() {
+				result := eval("false and notDefined")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns the value that is not true", func() {
+				So(eval("1 and nil").Value, ShouldResemble, Nothing)
+				So(eval("nil and 1").Value, ShouldResemble, Nothing)
+				So(eval("false and nil").Value, ShouldResemble, fnBool{value: false})
+			})
+
+		})
+
+		Convey("or", func() {
+
+			Convey("returns true if one value is true", func() {
+				result := eval("true or false")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false with two false values", func() {
+				result := eval("false or false")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns the first true value", func() {
+				result := eval("false or 2")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "2"})
+			})
+
+			Convey("does not evaluate the second value if the first is true", func() {
+				result := eval("true or notDefined")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns the last value if neither are true", func() {
+				So(eval("false or nil").Value, ShouldResemble, Nothing)
+				So(eval("nil or false").Value, ShouldResemble, fnBool{value: false})
 			})
 
-			Convey("divides an integer and a float", func() {
-				result := eval("2.5 / 5")
+			Convey("can provide a default for nil", func() {
+				result := eval("name = nil; name or \"default\"")
 				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, number{value: "0.5"})
+				So(result.Value, ShouldResemble, fnString{value: "default"})
 			})
 
 		})
 
-		Convey("and", func() {
+		Convey("nil", func() {
 
-			Convey("returns true with two true boolean values", func() {
-				result := eval("true and true")
+			Convey("is false", func() {
+				result := eval("Boolean(nil)")
 				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, fnBool{value: true})
+				So(result.Value, ShouldResemble, fnBool{value: false})
 			})
 
-			Convey("returns false if one value is false", func() {
-				result := eval("false and true")
+			Convey("can be converted to a string", func() {
+				result := eval("String(nil)")
 				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldResemble, fnBool{value: false})
+				So(result.Value, ShouldResemble, fnString{value: "nil"})
 			})
 
-		})
-
-		Convey("or", func() {
-
-			Convey("returns true if one value is true", func() {
-				result := eval("true or false")
+			Convey("is equal to itself", func() {
+				result := eval("nil eq nil")
 				So(result.Error, ShouldBeNil)
 				So(result.Value, ShouldResemble, fnBool{value: true})
 			})
 
-			Convey("returns false with two false values", func() {
-				result := eval("false or false")
+			Convey("is not equal to false", func() {
+				result := eval("nil eq false")
 				So(result.Error, ShouldBeNil)
 				So(result.Value, ShouldResemble, fnBool{value: false})
 			})
 
+			Convey("is returned by functions with empty bodies", func() {
+				result := eval("f = () { }; f()")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, Nothing)
+			})
+
+			Convey("is returned by each", func() {
+				result := eval("List(1).each((x) { x })")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, Nothing)
+			})
+
 		})
 
 		Convey("not", func() {
@@ -525,6 +1239,103 @@ func TestExecute(t *testing.T) {
 				So(result.Value, ShouldResemble, fnBool{value: false})
 			})
 
+			Convey("returns false for values of different types", func() {
+				result := eval("1 eq \"1\"")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns true if two lists have equal items", func() {
+				result := eval("List(1, List(\"two\")) eq List(1, List(\"two\"))")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false if two lists have different items", func() {
+				result := eval("List(1, 2) eq List(1, 3)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns true if two blocks have equal definitions", func() {
+				result := eval("a = { x = 1; y = List(2) }; b = { x = 1; y = List(2) }; a eq b")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false if two blocks have different definitions", func() {
+				result := eval("a = { x = 1 }; b = { x = 1; y = 2 }; a eq b")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("compares blocks containing themselves", func() {
+				result := eval("a = { x = 1 }; a.self = a; b = { x = 1 }; b.self = b; a eq b")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns true if a function is compared to itself", func() {
+				result := eval("f = (x) { x }; f eq f")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false for different functions with the same definition", func() {
+				result := eval("f = (x) { x }; g = (x) { x }; f eq g")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+		})
+
+		Convey("same", func() {
+
+			Convey("returns true for the same block", func() {
+				result := eval("a = { x = 1 }; b = a; same(a, b)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false for equal but different blocks", func() {
+				result := eval("a = { x = 1 }; b = { x = 1 }; same(a, b)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
+			Convey("returns true for equal numbers", func() {
+				result := eval("same(1, 1)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns true for the same list", func() {
+				result := eval("a = List(); b = a; same(a, b)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("returns false for equal but different lists", func() {
+				So(eval("same(List(), List())").Value, ShouldResemble, fnBool{value: false})
+				So(eval("same(List(1), List(1))").Value, ShouldResemble, fnBool{value: false})
+			})
+
+		})
+
+		Convey("hash", func() {
+
+			Convey("is the same for equal values", func() {
+				result := eval("hash(List(1, { x = 2 })) eq hash(List(1, { x = 2 }))")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: true})
+			})
+
+			Convey("differs for values of different types", func() {
+				result := eval("hash(1) eq hash(\"1\")")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, fnBool{value: false})
+			})
+
 		})
 
 		Convey("print", func() {
@@ -534,10 +1345,368 @@ func TestExecute(t *testing.T) {
 				So(result.Error, ShouldBeNil)
 			})
 
-			Convey("returns no value", func() {
+			Convey("returns nil", func() {
 				result := eval("print(1)")
 				So(result.Error, ShouldBeNil)
-				So(result.Value, ShouldBeNil)
+				So(result.Value, ShouldResemble, Nothing)
+			})
+
+			Convey("separates values with spaces and ends with a newline", func() {
+				So(output("print(1, \"a\", true)"), ShouldEqual, "1 a true\n")
+			})
+
+			Convey("takes sep and end by name", func() {
+				So(output("print(1, 2, sep: \", \", end: \"!\")"), ShouldEqual, "1, 2!")
+			})
+
+		})
+
+		Convey("eprint writes to stderr", func() {
+			var buffer bytes.Buffer
+			stderr = &buffer
+			defer func() { stderr = os.Stderr }()
+
+			eval("eprint(\"oops\", 1)")
+			So(buffer.String(), ShouldEqual, "oops 1\n")
+		})
+
+		Convey("write writes values without a newline", func() {
+			So(output("write(\"a\", 1); write(\"b\")"), ShouldEqual, "a1b")
+		})
+
+		Convey("readLine and readAll", func() {
+			stdin = bufio.NewReader(strings.NewReader("one\ntwo\nthree\n"))
+			defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+			So(eval("readLine()").Value, ShouldResemble, FnString("one"))
+			So(eval("readAll()").Value, ShouldResemble, FnString("two\nthree\n"))
+
+			Convey("readLine returns nil at the end of the input", func() {
+				So(eval("readLine()").Value, ShouldResemble, Nothing)
+			})
+		})
+
+		Convey("format", func() {
+
+			Convey("replaces placeholders with values", func() {
+				result := eval("format(\"%s has %d items costing %.2f (100%%)\", \"cart\", 3, 9.5)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, FnString("cart has 3 items costing 9.50 (100%)"))
+			})
+
+			Convey("returns an error if the values do not match the placeholders", func() {
+				So(eval("format(\"%s %s\", 1)").Error, ShouldNotBeNil)
+				So(eval("format(\"%s\", 1, 2)").Error, ShouldNotBeNil)
+				So(eval("format(\"%d\", \"a\")").Error, ShouldNotBeNil)
+				So(eval("format(\"%x\", 1)").Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error if the template is not a string", func() {
+				So(eval("format(1)").Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error for a precision on a verb that does not use one", func() {
+				So(eval("format(\"%.2s\", \"abc\")").Error, ShouldNotBeNil)
+				So(eval("format(\"%.2d\", 1)").Error, ShouldNotBeNil)
+				So(eval("format(\"%2%\")").Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error for a precision that is not a `.` followed by digits", func() {
+				for _, template := range []string{"%1.2.3f", "%2f", "%.f", "%..2f"} {
+					result := eval("format(\"" + template + "\", 1)")
+					So(result.Error, ShouldNotBeNil)
+					So(result.Error.Error(), ShouldStartWith, "format:")
+				}
+			})
+
+		})
+
+		Convey("exit", func() {
+
+			Convey("stops the program with an ExitError", func() {
+				result := eval("f = () { exit(3) }; f(); print(1)")
+				So(result.Error, ShouldResemble, ExitError{Code: 3})
+			})
+
+			Convey("returns an error if the code is not a number", func() {
+				result := eval("exit(\"a\")")
+				So(result.Error, ShouldNotResemble, ExitError{})
+				So(result.Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error if the code is not a whole number from 0 to 255", func() {
+				for _, code := range []string{"256", "1.5", "0 - 1"} {
+					result := eval("exit(" + code + ")")
+					So(result.Error, ShouldNotBeNil)
+					So(result.Error.Error(), ShouldContainSubstring, "exit expects a whole number from 0 to 255")
+				}
+			})
+
+		})
+
+		Convey("System", func() {
+
+			Convey("has the program's arguments", func() {
+				SetArgs([]string{"a", "b"})
+				defer SetArgs([]string{})
+
+				result := eval("System.args")
+				So(result.Value.(list).Items, ShouldResemble, []fnScope{FnString("a"), FnString("b")})
+			})
+
+			Convey("has the same args list each time", func() {
+				So(eval("x = System.args; same(x, x)").Value, ShouldResemble, FnBool(true))
+				So(eval("same(System.args, System.args)").Value, ShouldResemble, FnBool(true))
+			})
+
+			Convey("has environment variables", func() {
+				os.Setenv("FN_TEST_VARIABLE", "set")
+				defer os.Unsetenv("FN_TEST_VARIABLE")
+
+				So(eval("System.env(\"FN_TEST_VARIABLE\")").Value, ShouldResemble, FnString("set"))
+				So(eval("System.env(\"FN_TEST_UNSET_VARIABLE\")").Value, ShouldResemble, Nothing)
+			})
+
+			Convey("has the current directory", func() {
+				cwd, _ := os.Getwd()
+				So(eval("System.cwd").Value, ShouldResemble, FnString(cwd))
+			})
+
+			Convey("can exit", func() {
+				So(eval("System.exit(2)").Error, ShouldResemble, ExitError{Code: 2})
+			})
+
+			Convey("cannot be changed", func() {
+				So(eval("System.foo = 1").Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("File", func() {
+			dir, _ := ioutil.TempDir("", "fn-file-test")
+			defer os.RemoveAll(dir)
+
+			// Returns the result of the code with `dir` defined as the temporary directory.
+			inDir := func(code string) EvalResult {
+				return eval("dir = \"" + dir + "\"; " + code)
+			}
+
+			Convey("writes, appends and reads files", func() {
+				result := inDir("path = File.join(dir, \"a.txt\"); File.write(path, \"one\n\"); File.append(path, \"two\"); File.read(path)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, FnString("one\ntwo"))
+			})
+
+			Convey("reads lines one at a time", func() {
+				result := inDir("path = File.join(dir, \"a.txt\"); File.write(path, \"one\ntwo\n\"); lines = File.lines(path); lines.toList()")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{FnString("one"), FnString("two")}})
+			})
+
+			Convey("makes, lists and removes directories", func() {
+				result := inDir("sub = File.join(dir, \"sub\"); File.mkdir(sub); File.write(File.join(sub, \"b.fn\"), \"\"); File.list(sub)")
+				So(result.Value, ShouldResemble, list{Items: []fnScope{FnString("b.fn")}})
+
+				result = inDir("sub = File.join(dir, \"sub\"); File.remove(File.join(sub, \"b.fn\")); File.exists(File.join(sub, \"b.fn\"))")
+				So(result.Value, ShouldResemble, FnBool(false))
+			})
+
+			Convey("returns an error for missing files", func() {
+				result := inDir("File.read(File.join(dir, \"missing.txt\"))")
+				So(result.Error, ShouldNotBeNil)
+				So(result.Error.Error(), ShouldEndWith, "missing.txt: no such file or directory")
+			})
+
+			Convey("has path helpers", func() {
+				So(eval("File.base(\"a/b.fn\")").Value, ShouldResemble, FnString("b.fn"))
+				So(eval("File.dir(\"a/b.fn\")").Value, ShouldResemble, FnString("a"))
+				So(eval("File.ext(\"a/b.fn\")").Value, ShouldResemble, FnString(".fn"))
+			})
+
+			Convey("only uses paths inside the sandbox", func() {
+				Sandbox = dir
+				defer func() { Sandbox = "" }()
+
+				So(inDir("File.write(File.join(dir, \"a.txt\"), \"a\")").Error, ShouldBeNil)
+				So(inDir("File.read(File.join(dir, \"..\", \"a.txt\"))").Error, ShouldNotBeNil)
+				So(eval("File.exists(\"/\")").Error, ShouldNotBeNil)
+			})
+
+			Convey("follows links before `..`, as the OS does", func() {
+				sandbox, outside := filepath.Join(dir, "sandbox"), filepath.Join(dir, "outside")
+				os.MkdirAll(filepath.Join(outside, "sub"), 0755)
+				os.MkdirAll(filepath.Join(sandbox, "sub"), 0755)
+				ioutil.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+				ioutil.WriteFile(filepath.Join(sandbox, "a.txt"), []byte("a"), 0644)
+				os.Symlink(filepath.Join(outside, "sub"), filepath.Join(sandbox, "out"))
+				os.Symlink(filepath.Join(sandbox, "sub"), filepath.Join(sandbox, "in"))
+
+				Sandbox = sandbox
+				defer func() { Sandbox = "" }()
+
+				So(eval("File.read(\""+sandbox+"/out/../secret.txt\")").Error, ShouldNotBeNil)
+				So(eval("File.write(\""+sandbox+"/out/../pwned.txt\", \"x\")").Error, ShouldNotBeNil)
+				_, err := os.Stat(filepath.Join(outside, "pwned.txt"))
+				So(os.IsNotExist(err), ShouldBeTrue)
+
+				So(eval("File.read(\""+sandbox+"/in/../a.txt\")").Value, ShouldResemble, FnString("a"))
+			})
+
+		})
+
+		Convey("JSON", func() {
+			dir, _ := ioutil.TempDir("", "fn-json-test")
+			defer os.RemoveAll(dir)
+
+			// fn strings cannot contain quotes, so JSON objects are read from a file.
+			path := filepath.Join(dir, "data.json")
+			ioutil.WriteFile(path, []byte(`{"name": "fn", "tags": [1, 2.5, true, null], "nested": {"x": 1e3}}`), 0644)
+			parsed := "data = JSON.parse(File.read(\"" + path + "\")); "
+
+			Convey("parses objects into blocks", func() {
+				So(eval(parsed+"data.name").Value, ShouldResemble, FnString("fn"))
+				So(eval(parsed+"data.nested.x").Value, ShouldResemble, number{value: "1e3"})
+				So(eval(parsed+"data.nested.x + 1").Value, ShouldResemble, number{value: "1001"})
+			})
+
+			Convey("keeps the text of numbers", func() {
+				result := eval("JSON.parse(\"12345678901234567890\")")
+				So(result.Value, ShouldResemble, number{value: "12345678901234567890"})
+
+				result = eval("JSON.stringify(JSON.parse(\"12345678901234567890\"))")
+				So(result.Value, ShouldResemble, FnString("12345678901234567890"))
+			})
+
+			Convey("returns an error for numbers that are out of range", func() {
+				result := eval("JSON.parse(\"1e400\")")
+				So(result.Error, ShouldNotBeNil)
+				So(result.Error.Error(), ShouldContainSubstring, "1e400 is out of range")
+			})
+
+			Convey("returns an error for keys that cannot be block attributes", func() {
+				keys := []string{`"eq"`, `"+"`, `"asString"`, `"call"`, `"first-name"`, `""`}
+				for _, key := range keys {
+					ioutil.WriteFile(path, []byte(`{`+key+`: 5}`), 0644)
+					result := eval("JSON.parse(File.read(\"" + path + "\"))")
+
+					So(result.Error, ShouldNotBeNil)
+					So(result.Error.Error(), ShouldContainSubstring, "cannot be the name of a block attribute")
+				}
+			})
+
+			Convey("parses arrays into lists", func() {
+				result := eval("JSON.parse(\"[1, 2.5, true, null]\")")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{
+					number{value: "1"}, number{value: "2.5"}, FnBool(true), Nothing,
+				}})
+			})
+
+			Convey("returns an error for invalid JSON", func() {
+				So(eval("JSON.parse(\"[1,\")").Error, ShouldNotBeNil)
+				So(eval("JSON.parse(\"1 2\")").Error, ShouldNotBeNil)
+				So(eval("JSON.parse(1)").Error, ShouldNotBeNil)
+			})
+
+			Convey("stringifies values", func() {
+				result := eval(parsed + "JSON.stringify(data)")
+
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, FnString(`{"name":"fn","nested":{"x":1e3},"tags":[1,2.5,true,null]}`))
+			})
+
+			Convey("indents by a number of spaces or a string", func() {
+				So(eval("JSON.stringify(List(1), 2)").Value, ShouldResemble, FnString("[\n  1\n]"))
+				So(eval("JSON.stringify(List(1), indent: \"\t\")").Value, ShouldResemble, FnString("[\n\t1\n]"))
+			})
+
+			Convey("returns an error for negative or fractional indents", func() {
+				So(eval("JSON.stringify(List(1), 0 - 1)").Error, ShouldNotBeNil)
+				So(eval("JSON.stringify(List(1), 1.5)").Error, ShouldNotBeNil)
+			})
+
+			Convey("returns an error for functions and cyclic blocks", func() {
+				So(eval("JSON.stringify((x) { x })").Error, ShouldNotBeNil)
+				So(eval("b = { }; b.self = b; JSON.stringify(b)").Error, ShouldNotBeNil)
+			})
+
+		})
+
+		Convey("partial", func() {
+
+			Convey("returns a function with the arguments applied", func() {
+				result := eval("add = (a, b) { a + b }; addOne = partial(add, 1); addOne(2)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("waits for a call even if all arguments are applied", func() {
+				result := eval("add = (a, b) { a + b }; addAll = partial(add, 1, 2); addAll()")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+			Convey("keeps the defaults of the remaining arguments", func() {
+				result := eval("f = (a, b = 2) { a + b }; g = partial(f); List(g(1), g(1, 5))")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{number{value: "3"}, number{value: "6"}}})
+			})
+
+			Convey("keeps the rest argument", func() {
+				result := eval("f = (a, ...rest) { rest }; g = partial(f, 1, 2); g(3)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, list{Items: []fnScope{number{value: "2"}, number{value: "3"}}})
+			})
+
+			Convey("can apply arguments to callable blocks", func() {
+				result := eval("x = { call = (a, b) { a - b } }; y = partial(x, 5); y(1)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+		})
+
+		Convey("compose", func() {
+
+			Convey("applies functions from right to left", func() {
+				result := eval("double = (x) { x * 2 }; inc = (x) { x + 1 }; f = compose(double, inc); f(1)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+		})
+
+		Convey("pipe", func() {
+
+			Convey("applies functions from left to right", func() {
+				result := eval("double = (x) { x * 2 }; inc = (x) { x + 1 }; f = pipe(double, inc); f(1)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "3"})
+			})
+
+		})
+
+		Convey("|>", func() {
+
+			Convey("passes the value to the function", func() {
+				result := eval("double = (x) { x * 2 }; 2 |> double")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "4"})
+			})
+
+			Convey("can be chained", func() {
+				result := eval("double = (x) { x * 2 }; add = (a, b) { a + b }; 2 |> double |> add(1)")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "5"})
+			})
+
+			Convey("applies the function after arithmetic", func() {
+				result := eval("double = (x) { x * 2 }; 1 + 2 |> double")
+				So(result.Error, ShouldBeNil)
+				So(result.Value, ShouldResemble, number{value: "6"})
 			})
 
 		})
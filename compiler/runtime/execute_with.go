@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// Execute a `with` function call.
+// Returns a copy of the block on the left with the definitions
+// of the block on the right added, replacing any with the same name.
+// The original block is not changed.
+// Functions are copied as they are, so they still use the names of the
+// block they were defined in, as functions inherited with extend do.
+// Records can be updated in the same way, but only their fields can be changed.
+func execWith(base Expression, changes Expression, scope fnScope) EvalResult {
+	execBase := exec(base, scope)
+	if execBase.Error != nil {
+		return execBase
+	}
+
+	execChanges := exec(changes, scope)
+	if execChanges.Error != nil {
+		return execChanges
+	}
+
+	changesBlock, ok := execChanges.Value.(Scope)
+	if !ok {
+		return EvalResult{Error: errors.New(fmt.Sprintf("with needs a block of changes, got %s", execChanges.Value.String()))}
+	}
+
+	if rec, ok := execBase.Value.(record); ok {
+		newRecord, err := rec.with(changesBlock.definitions)
+		return EvalResult{Value: newRecord, Scope: scope, Error: err}
+	}
+
+	baseBlock, ok := execBase.Value.(Scope)
+	if !ok {
+		return EvalResult{Error: errors.New(fmt.Sprintf("with can only be used on blocks and records, got %s", execBase.Value.String()))}
+	}
+
+	// Unchanged definitions are shared with the original block.
+	definitions := defMap{}
+	for id, value := range baseBlock.definitions {
+		definitions[id] = value
+	}
+
+	for id, value := range changesBlock.definitions {
+		definitions[id] = value
+	}
+
+	newBlock := Scope{
+		parent:      baseBlock.parent,
+		definitions: definitions,
+		proto:       baseBlock.proto,
+	}
+
+	return EvalResult{Value: newBlock, Scope: scope}
+}
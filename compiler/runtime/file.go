@@ -0,0 +1,430 @@
+package runtime
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// If set, File can only use paths inside this directory.
+// Set by the CLI's --sandbox flag.
+var Sandbox = ""
+
+// fileModule is the scope holding the file system functions.
+// It is available in fn as `File`.
+type fileModule struct{}
+
+func (f fileModule) Definitions() defMap {
+	return defMap{
+		"read":   fn([]string{"path"}, f.read).named("File.read"),
+		"write":  fn([]string{"path", "text"}, f.write).named("File.write"),
+		"append": fn([]string{"path", "text"}, f.append).named("File.append"),
+		"exists": fn([]string{"path"}, f.exists).named("File.exists"),
+		"list":   fn([]string{"dir"}, f.list).named("File.list"),
+		"lines":  fn([]string{"path"}, f.lines).named("File.lines"),
+		"mkdir":  fn([]string{"path"}, f.mkdir).named("File.mkdir"),
+		"remove": fn([]string{"path"}, f.remove).named("File.remove"),
+
+		"join": fnWithRest([]string{}, "parts", f.join).named("File.join"),
+		"base": fn([]string{"path"}, f.base).named("File.base"),
+		"dir":  fn([]string{"path"}, f.dir).named("File.dir"),
+		"ext":  fn([]string{"path"}, f.ext).named("File.ext"),
+
+		"asString": fn([]string{}, f.asString),
+	}
+}
+
+func (f fileModule) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on File!")
+}
+
+func (f fileModule) String() string {
+	return "File"
+}
+
+func (f fileModule) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("File called as a function!")
+}
+
+func (f fileModule) Value() interface{} {
+	return f
+}
+
+func (f fileModule) asString(args []fnScope) (fnScope, error) {
+	return FnString(f.String()), nil
+}
+
+func (f fileModule) read(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("read", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fileError("read", args[0], err)
+	}
+
+	return FnString(string(contents)), nil
+}
+
+func (f fileModule) write(args []fnScope) (fnScope, error) {
+	return writeFile("write", args, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+func (f fileModule) append(args []fnScope) (fnScope, error) {
+	return writeFile("append", args, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+// Writes the text in args[1] to the path in args[0], opening the file with the flags.
+func writeFile(name string, args []fnScope, flags int) (fnScope, error) {
+	path, err := sandboxedPath(name, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := stringArgument(name, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fileError(name, args[0], err)
+	}
+
+	defer file.Close()
+
+	if _, err := file.WriteString(text); err != nil {
+		return nil, fileError(name, args[0], err)
+	}
+
+	return Nothing, nil
+}
+
+func (f fileModule) exists(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("exists", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	return FnBool(err == nil), nil
+}
+
+// Returns the names in the directory, sorted.
+func (f fileModule) list(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("list", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, fileError("list", args[0], err)
+	}
+
+	names := []fnScope{}
+	for _, entry := range entries {
+		names = append(names, FnString(entry.Name()))
+	}
+
+	return List(names)
+}
+
+func (f fileModule) lines(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("lines", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return fileLines{path: path, name: args[0].String()}, nil
+}
+
+func (f fileModule) mkdir(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("mkdir", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fileError("mkdir", args[0], err)
+	}
+
+	return Nothing, nil
+}
+
+// Removes the file, or the directory if it is empty.
+func (f fileModule) remove(args []fnScope) (fnScope, error) {
+	path, err := sandboxedPath("remove", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return nil, fileError("remove", args[0], err)
+	}
+
+	return Nothing, nil
+}
+
+func (f fileModule) join(args []fnScope) (fnScope, error) {
+	parts := []string{}
+	for _, part := range args[0].(list).Items {
+		str, err := stringArgument("join", part)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, str)
+	}
+
+	return FnString(filepath.Join(parts...)), nil
+}
+
+func (f fileModule) base(args []fnScope) (fnScope, error) {
+	return pathFunction("base", args[0], filepath.Base)
+}
+
+func (f fileModule) dir(args []fnScope) (fnScope, error) {
+	return pathFunction("dir", args[0], filepath.Dir)
+}
+
+func (f fileModule) ext(args []fnScope) (fnScope, error) {
+	return pathFunction("ext", args[0], filepath.Ext)
+}
+
+// Applies the function to the path, which must be a string.
+func pathFunction(name string, path fnScope, function func(string) string) (fnScope, error) {
+	str, err := stringArgument(name, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return FnString(function(str)), nil
+}
+
+// Returns the value as a Go string, or an error if it is not a string.
+func stringArgument(name string, value fnScope) (string, error) {
+	str, ok := value.(fnString)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("File.%s expects a string, got %s", name, value.String()))
+	}
+
+	return str.value, nil
+}
+
+// Returns an error describing why the file operation failed,
+// without Go's description of the operation.
+func fileError(name string, path fnScope, err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+
+	return errors.New(fmt.Sprintf("File.%s failed for %s: %s", name, path.String(), err.Error()))
+}
+
+// Returns the path as a Go string, checking that it is inside the sandbox if there is one.
+// In the sandbox, the path returned has its directory resolved,
+// so it names the file that was checked.
+func sandboxedPath(name string, path fnScope) (string, error) {
+	str, err := stringArgument(name, path)
+	if err != nil {
+		return "", err
+	}
+
+	if Sandbox == "" {
+		return str, nil
+	}
+
+	root, err := realPath(Sandbox)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("File.%s cannot use the sandbox %s: %s", name, Sandbox, err.Error()))
+	}
+
+	resolved, err := realPath(str)
+	if err != nil {
+		return "", fileError(name, path, err)
+	}
+
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", errors.New(fmt.Sprintf("File.%s cannot use %s: it is outside the sandbox %s", name, str, Sandbox))
+	}
+
+	// The last part is not resolved, so File.remove removes a link and not its target.
+	dir, base := ".", str
+	if idx := strings.LastIndex(str, string(filepath.Separator)); idx != -1 {
+		dir, base = str[:idx+1], str[idx+1:]
+	}
+
+	resolvedDir, err := realPath(dir)
+	if err != nil {
+		return "", fileError(name, path, err)
+	}
+
+	return filepath.Join(resolvedDir, base), nil
+}
+
+// Returns the absolute path with symbolic links resolved,
+// so links cannot be used to leave the sandbox.
+// Links are resolved before the `..` after them, as the OS does.
+// Parts of the path that do not exist yet are kept as they are.
+func realPath(path string) (string, error) {
+	separator := string(filepath.Separator)
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+
+		path = cwd + separator + path
+	}
+
+	resolved := filepath.VolumeName(path) + separator
+	parts := strings.Split(path[len(filepath.VolumeName(path)):], separator)
+	links := 0
+
+	for len(parts) > 0 {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, part)
+		info, err := os.Lstat(next)
+		if os.IsNotExist(err) {
+			// The OS cannot go back out of a directory that does not exist.
+			for _, rest := range parts {
+				if rest == ".." {
+					return "", err
+				}
+			}
+
+			return filepath.Join(append([]string{next}, parts...)...), nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		links += 1
+		if links > 255 {
+			return "", errors.New(fmt.Sprintf("too many links in %s", path))
+		}
+
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+
+		if filepath.IsAbs(target) {
+			resolved = filepath.VolumeName(target) + separator
+			target = target[len(filepath.VolumeName(target)):]
+		}
+
+		parts = append(strings.Split(target, separator), parts...)
+	}
+
+	return resolved, nil
+}
+
+// fileLines is the lines of a file, read as they are used.
+type fileLines struct {
+	path string
+	name string // The path as given, for messages.
+}
+
+func (lines fileLines) Definitions() defMap {
+	return defMap{
+		"each":     fn([]string{"f"}, lines.each),
+		"toList":   fn([]string{}, lines.toList),
+		"asString": fn([]string{}, lines.asString),
+	}
+}
+
+func (lines fileLines) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on file lines!")
+}
+
+func (lines fileLines) String() string {
+	return fmt.Sprintf("File.lines(%s)", lines.name)
+}
+
+func (lines fileLines) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("File lines called as a function!")
+}
+
+func (lines fileLines) Value() interface{} {
+	return lines.path
+}
+
+func (lines fileLines) asString(args []fnScope) (fnScope, error) {
+	return FnString(lines.String()), nil
+}
+
+// Calls f with each line, reading one line at a time.
+func (lines fileLines) each(args []fnScope) (fnScope, error) {
+	err := lines.read(func(line string) error {
+		_, err := args[0].Call([]fnScope{FnString(line)})
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Nothing, nil
+}
+
+// Returns every line in a list.
+func (lines fileLines) toList(args []fnScope) (fnScope, error) {
+	items := []fnScope{}
+	err := lines.read(func(line string) error {
+		items = append(items, FnString(line))
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return List(items)
+}
+
+// Calls use with each line of the file, stopping at the first error.
+func (lines fileLines) read(use func(string) error) error {
+	file, err := os.Open(lines.path)
+	if err != nil {
+		return fileError("lines", FnString(lines.name), err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := use(scanner.Text()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fileError("lines", FnString(lines.name), err)
+	}
+
+	return nil
+}
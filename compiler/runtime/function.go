@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 )
 
 // Alias for a function that can be used within fn.
@@ -15,12 +16,33 @@ func (names argNames) String() string {
 	return fmt.Sprintf("(%s)", strings.Join(names, ", "))
 }
 
+// Returns the position of the argument with the given name,
+// or -1 if there is no such argument.
+func (names argNames) indexOf(name string) int {
+	for idx, argName := range names {
+		if argName == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
 // A functionScope wraps internal functions as scopes.
 type functionScope struct {
+	Name          string // Set when the function is first defined.
 	ArgumentNames argNames
+	Defaults      defMap          // Default values, by argument name.
+	RestName      string          // The name of the rest argument, if any.
+	Lazy          map[string]bool // Arguments that are passed unevaluated, by name.
+	Options       map[string]bool // Arguments that can only be given by name.
 	value         fnFunc
+	id            uint64 // Identifies the function for equality.
 }
 
+// The id of the most recently created function.
+var lastFunctionId uint64
+
 func (fs functionScope) Definitions() defMap {
 	return defMap{
 		"asString": fn([]string{}, fs.asString),
@@ -32,23 +54,31 @@ func (fn functionScope) Define(id string, value fnScope) (fnScope, error) {
 }
 
 func (fn functionScope) String() string {
-	return fmt.Sprintf("%s { ... }", fn.ArgumentNames.String())
+	return fmt.Sprintf("%s { ... }", fn.signature())
 }
 
+// Calls the function.
+// The function value receives one argument per ArgumentName
+// (with defaults filled in), followed by a list of any rest arguments.
+//
+// If arguments without defaults are missing, the function is partially applied:
+// a new function awaiting the missing arguments is returned.
 func (fn functionScope) Call(args []fnScope) (fnScope, error) {
-	if len(args) != len(fn.ArgumentNames) {
-		return nil, errors.New(fmt.Sprintf(
-			"Argument number mismatch: got %i, need %i",
-			len(args),
-			len(fn.ArgumentNames),
-		))
+	boundArgs, missing, err := fn.bindArgs(args)
+	if err != nil {
+		return nil, err
 	}
 
-	return fn.value(args)
+	if len(missing) > 0 {
+		return fn.partial(args)
+	}
+
+	return fn.value(boundArgs)
 }
 
+// Functions are only equal to themselves, so compare by id.
 func (fn functionScope) Value() interface{} {
-	return fn
+	return fn.id
 }
 
 // Helper for use when defining built-in functions.
@@ -56,9 +86,240 @@ func fn(args []string, value fnFunc) functionScope {
 	return functionScope{
 		ArgumentNames: args,
 		value:         value,
+		id:            atomic.AddUint64(&lastFunctionId, 1),
+	}
+}
+
+// Helper for use when defining built-in functions with a rest argument.
+// The rest argument is passed to the value as the last argument, as a list.
+func fnWithRest(args []string, rest string, value fnFunc) functionScope {
+	function := fn(args, value)
+	function.RestName = rest
+	return function
+}
+
+// Helper for use when defining built-in functions that take any number of values
+// and options that can only be given by name, e.g. `print(a, b, sep: ", ")`.
+// The options are passed to the value first, followed by the values as a list.
+func fnWithOptions(options []string, defaults defMap, rest string, value fnFunc) functionScope {
+	function := fnWithRest(options, rest, value)
+	function.Defaults = defaults
+	function.Options = map[string]bool{}
+	for _, option := range options {
+		function.Options[option] = true
 	}
+
+	return function
 }
 
 func (fn functionScope) asString(args []fnScope) (fnScope, error) {
 	return FnString(fn.String()), nil
 }
+
+// Returns the argument list of the function, e.g. `(a, b = 2, ...rest)`
+func (fn functionScope) signature() string {
+	args, options := []string{}, []string{}
+	for _, name := range fn.ArgumentNames {
+		if fn.Options[name] {
+			options = append(options, fmt.Sprintf("%s: %q", name, fn.Defaults[name].String()))
+		} else if fn.isLazy(name) {
+			args = append(args, "lazy "+name)
+		} else if fn.Defaults[name] != nil {
+			args = append(args, fmt.Sprintf("%s = %s", name, fn.Defaults[name].String()))
+		} else {
+			args = append(args, name)
+		}
+	}
+
+	if fn.RestName != "" {
+		args = append(args, "..."+fn.RestName)
+	}
+
+	return argNames(append(args, options...)).String()
+}
+
+// Returns true if the named argument should be passed unevaluated.
+func (fn functionScope) isLazy(name string) bool {
+	return fn.Lazy[name]
+}
+
+// Returns a description of the function for use in error messages.
+func (fn functionScope) describe() string {
+	if fn.Name != "" {
+		return fn.Name
+	}
+
+	return "function " + fn.signature()
+}
+
+// Returns the function with the given name,
+// unless it has already been named.
+func (fn functionScope) named(name string) functionScope {
+	if fn.Name == "" {
+		fn.Name = name
+	}
+
+	return fn
+}
+
+// Matches the given arguments to the ArgumentNames of the function.
+// Named arguments are matched by name, and defaults fill any gaps.
+// Returns the names of any arguments that are still missing.
+func (fn functionScope) bindArgs(args []fnScope) ([]fnScope, []string, error) {
+	boundArgs, rest, err := fn.placeArgs(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing := []string{}
+	for idx, name := range fn.ArgumentNames {
+		if boundArgs[idx] != nil {
+			continue
+		}
+
+		if fn.Defaults[name] != nil {
+			boundArgs[idx] = fn.Defaults[name]
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	if fn.RestName != "" {
+		boundArgs = append(boundArgs, newList(rest))
+	}
+
+	return boundArgs, missing, nil
+}
+
+// Places the given arguments in the positions of the ArgumentNames they match.
+// Positions with no argument are left as nil;
+// positional arguments beyond the ArgumentNames are returned as the rest.
+func (fn functionScope) placeArgs(args []fnScope) ([]fnScope, []fnScope, error) {
+	boundArgs := make([]fnScope, len(fn.ArgumentNames))
+	rest := []fnScope{}
+	positionalCount := 0
+
+	// Options are skipped by positional arguments.
+	positions := []int{}
+	for idx, name := range fn.ArgumentNames {
+		if !fn.Options[name] {
+			positions = append(positions, idx)
+		}
+	}
+
+	for _, arg := range args {
+		named, isNamed := arg.(namedArgument)
+		if !isNamed {
+			if positionalCount < len(positions) {
+				boundArgs[positions[positionalCount]] = arg
+			} else if fn.RestName != "" {
+				rest = append(rest, arg)
+			} else {
+				return nil, nil, errors.New(fmt.Sprintf(
+					"Argument number mismatch calling %s: got more than %d",
+					fn.describe(),
+					len(positions),
+				))
+			}
+
+			positionalCount += 1
+			continue
+		}
+
+		idx := fn.ArgumentNames.indexOf(named.name)
+		if idx == -1 {
+			return nil, nil, errors.New(fmt.Sprintf("%s has no argument named %s", fn.describe(), named.name))
+		}
+
+		if boundArgs[idx] != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Argument %s given more than once calling %s", named.name, fn.describe()))
+		}
+
+		boundArgs[idx] = named.value
+	}
+
+	return boundArgs, rest, nil
+}
+
+// Returns the function with the given arguments applied, awaiting the rest.
+// Arguments that were not given keep their defaults.
+func (fn functionScope) partial(args []fnScope) (functionScope, error) {
+	given, givenRest, err := fn.placeArgs(args)
+	if err != nil {
+		return functionScope{}, err
+	}
+
+	remaining := []string{}
+	for idx, name := range fn.ArgumentNames {
+		if given[idx] == nil {
+			remaining = append(remaining, name)
+		}
+	}
+
+	partial := fnWithRest(remaining, fn.RestName, func(args []fnScope) (fnScope, error) {
+		allArgs := make([]fnScope, len(given))
+		copy(allArgs, given)
+
+		next := 0
+		for idx := range fn.ArgumentNames {
+			if allArgs[idx] == nil {
+				allArgs[idx] = args[next]
+				next += 1
+			}
+		}
+
+		// Rest arguments given now follow those given before.
+		if fn.RestName != "" {
+			rest := append([]fnScope{}, givenRest...)
+			rest = append(rest, args[next].(list).Items...)
+			allArgs = append(allArgs, newList(rest))
+		}
+
+		return fn.value(allArgs)
+	})
+
+	partial.Name = fn.Name
+	partial.Defaults = fn.Defaults
+	partial.Options = fn.Options
+	partial.Lazy = fn.Lazy
+	return partial, nil
+}
+
+// A namedArgument carries a value given by name, e.g. `f(b: 3)`,
+// to the function being called.
+type namedArgument struct {
+	name  string
+	value fnScope
+}
+
+func (arg namedArgument) Definitions() defMap {
+	return arg.value.Definitions()
+}
+
+func (arg namedArgument) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on a named argument!")
+}
+
+func (arg namedArgument) String() string {
+	return fmt.Sprintf("%s: %s", arg.name, arg.value.String())
+}
+
+func (arg namedArgument) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("Named argument called as a function!")
+}
+
+func (arg namedArgument) Value() interface{} {
+	return arg.value.Value()
+}
+
+// Returns an error if any of the arguments are named.
+// Used by scopes that can be called, but have no argument names.
+func rejectNamedArgs(args []fnScope, callee string) error {
+	for _, arg := range args {
+		if named, isNamed := arg.(namedArgument); isNamed {
+			return errors.New(fmt.Sprintf("%s does not take named arguments (got %s)", callee, named.name))
+		}
+	}
+
+	return nil
+}
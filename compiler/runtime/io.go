@@ -0,0 +1,195 @@
+package runtime
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Where fn reads and writes; tests replace these.
+var (
+	stdin            = bufio.NewReader(os.Stdin)
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// print and eprint separate values with spaces and end with a newline.
+var printDefaults = defMap{
+	"sep": FnString(" "),
+	"end": FnString("\n"),
+}
+
+// Writes the values to stdout, separated by sep and followed by end.
+func fnPrint(args []fnScope) (fnScope, error) {
+	return printTo(stdout, args)
+}
+
+// As print, but to stderr.
+func fnEprint(args []fnScope) (fnScope, error) {
+	return printTo(stderr, args)
+}
+
+// Writes args[2] (the values) to the writer, separated by args[0] and followed by args[1].
+func printTo(writer io.Writer, args []fnScope) (fnScope, error) {
+	sep, end, values := args[0].String(), args[1].String(), args[2].(list).Items
+
+	strs := []string{}
+	for _, value := range values {
+		strs = append(strs, value.String())
+	}
+
+	fmt.Fprint(writer, strings.Join(strs, sep)+end)
+	return Nothing, nil
+}
+
+// Writes the values to stdout, with nothing between or after them.
+func fnWrite(args []fnScope) (fnScope, error) {
+	for _, value := range args[0].(list).Items {
+		fmt.Fprint(stdout, value.String())
+	}
+
+	return Nothing, nil
+}
+
+// Returns the next line of stdin without its newline, or nil at the end of the input.
+func fnReadLine(args []fnScope) (fnScope, error) {
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return Nothing, nil
+	}
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return FnString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), nil
+}
+
+// Returns the rest of stdin.
+func fnReadAll(args []fnScope) (fnScope, error) {
+	all, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	return FnString(string(all)), nil
+}
+
+// Returns the template with each placeholder replaced by the next value:
+//
+//	%s   the value as a string
+//	%d   a number, rounded to a whole number
+//	%f   a number; %.2f gives 2 decimal places
+//	%%   a percent sign
+func fnFormat(args []fnScope) (fnScope, error) {
+	str, ok := args[0].(fnString)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("format expects a string template, got %s", args[0].String()))
+	}
+
+	template, values := str.value, args[1].(list).Items
+
+	var result strings.Builder
+	next := 0
+
+	for idx := 0; idx < len(template); idx++ {
+		if template[idx] != '%' {
+			result.WriteByte(template[idx])
+			continue
+		}
+
+		// Read the placeholder, e.g. `%.2f`.
+		end := idx + 1
+		for end < len(template) && strings.IndexByte(".0123456789", template[end]) != -1 {
+			end++
+		}
+
+		if end == len(template) {
+			return nil, errors.New(fmt.Sprintf("format: %s ends in an unfinished placeholder", strconv.Quote(template)))
+		}
+
+		precision, verb := template[idx+1:end], template[end]
+		idx = end
+
+		if !isPrecision(precision) {
+			return nil, errors.New(fmt.Sprintf("format: %%%s%c has an invalid precision", precision, verb))
+		}
+
+		// Only %f uses a precision, so it is an error anywhere else.
+		if precision != "" && verb != 'f' {
+			return nil, errors.New(fmt.Sprintf("format: %%%s%c does not take a precision", precision, verb))
+		}
+
+		if verb == '%' {
+			result.WriteByte('%')
+			continue
+		}
+
+		if next == len(values) {
+			return nil, errors.New(fmt.Sprintf("format: %s needs more than %d values", strconv.Quote(template), len(values)))
+		}
+
+		value := values[next]
+		next += 1
+
+		formatted, err := formatValue(value, precision, verb)
+		if err != nil {
+			return nil, err
+		}
+
+		result.WriteString(formatted)
+	}
+
+	if next < len(values) {
+		return nil, errors.New(fmt.Sprintf("format: %s uses %d values, got %d", strconv.Quote(template), next, len(values)))
+	}
+
+	return FnString(result.String()), nil
+}
+
+// Returns true if the text between `%` and the verb is empty,
+// or a precision such as `.2`.
+func isPrecision(text string) bool {
+	if text == "" {
+		return true
+	}
+
+	if len(text) == 1 || text[0] != '.' {
+		return false
+	}
+
+	for _, char := range text[1:] {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Formats the value for the placeholder `%<precision><verb>`.
+func formatValue(value fnScope, precision string, verb byte) (string, error) {
+	switch verb {
+	case 's':
+		return value.String(), nil
+
+	case 'd', 'f':
+		num, ok := value.(number)
+		if !ok {
+			return "", errors.New(fmt.Sprintf("format: %%%c expects a number, got %s", verb, value.String()))
+		}
+
+		if verb == 'd' {
+			return strconv.FormatFloat(num.AsFloat(), 'f', 0, 64), nil
+		}
+
+		return fmt.Sprintf("%"+precision+"f", num.AsFloat()), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("format: unknown placeholder %%%c", verb))
+}
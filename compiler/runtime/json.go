@@ -0,0 +1,261 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jonnyarnold/fn-go/compiler/tokeniser"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// jsonModule is the scope holding the JSON functions.
+// It is available in fn as `JSON`.
+type jsonModule struct{}
+
+func (j jsonModule) Definitions() defMap {
+	stringify := fn([]string{"value", "indent"}, j.stringify).named("JSON.stringify")
+	stringify.Defaults = defMap{"indent": number{value: "0"}}
+
+	return defMap{
+		"parse":     fn([]string{"text"}, j.parse).named("JSON.parse"),
+		"stringify": stringify,
+		"asString":  fn([]string{}, j.asString),
+	}
+}
+
+func (j jsonModule) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on JSON!")
+}
+
+func (j jsonModule) String() string {
+	return "JSON"
+}
+
+func (j jsonModule) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("JSON called as a function!")
+}
+
+func (j jsonModule) Value() interface{} {
+	return j
+}
+
+func (j jsonModule) asString(args []fnScope) (fnScope, error) {
+	return FnString(j.String()), nil
+}
+
+// Returns the fn value of the JSON text.
+// Objects become blocks, arrays become lists and null becomes nil.
+// Object keys must be identifiers, and cannot be asString or call.
+func (j jsonModule) parse(args []fnScope) (fnScope, error) {
+	text, ok := args[0].(fnString)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("JSON.parse expects a string, got %s", args[0].String()))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text.value))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, errors.New(fmt.Sprintf("JSON.parse: %s", err.Error()))
+	}
+
+	if decoder.More() {
+		return nil, errors.New("JSON.parse: unexpected text after the value")
+	}
+
+	return fromJSON(decoded)
+}
+
+// Returns the value as JSON. The indent is a number of spaces or a string;
+// with no indent, the JSON is on one line.
+func (j jsonModule) stringify(args []fnScope) (fnScope, error) {
+	indent := ""
+	switch args[1].(type) {
+	case number:
+		spaces := args[1].(number).AsFloat()
+		if spaces != math.Trunc(spaces) || spaces < 0 {
+			return nil, errors.New(fmt.Sprintf("JSON.stringify expects a whole number of spaces, got %s", args[1].String()))
+		}
+
+		indent = strings.Repeat(" ", int(spaces))
+	case fnString:
+		indent = args[1].(fnString).value
+	default:
+		return nil, errors.New(fmt.Sprintf("JSON.stringify expects a number or string indent, got %s", args[1].String()))
+	}
+
+	text, err := ToJSON(args[0], indent)
+	if err != nil {
+		return nil, err
+	}
+
+	return FnString(text), nil
+}
+
+// Returns the fn value of a value decoded by encoding/json.
+func fromJSON(value interface{}) (fnScope, error) {
+	switch value.(type) {
+	case nil:
+		return Nothing, nil
+
+	case bool:
+		return FnBool(value.(bool)), nil
+
+	case string:
+		return FnString(value.(string)), nil
+
+	case json.Number:
+		// The text is kept, so large numbers are shown as they were written,
+		// but it must still be usable as a number.
+		text := string(value.(json.Number))
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, errors.New(fmt.Sprintf("JSON.parse: %s is out of range", text))
+		}
+
+		return number{value: text}, nil
+
+	case []interface{}:
+		items := []fnScope{}
+		for _, item := range value.([]interface{}) {
+			converted, err := fromJSON(item)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, converted)
+		}
+
+		return List(items)
+
+	case map[string]interface{}:
+		block := DefaultScope()
+		for id, item := range value.(map[string]interface{}) {
+			if !isAttributeName(id) {
+				return nil, errors.New(fmt.Sprintf("JSON.parse: %s cannot be the name of a block attribute", strconv.Quote(id)))
+			}
+
+			converted, err := fromJSON(item)
+			if err != nil {
+				return nil, err
+			}
+
+			block.definitions[id] = converted
+		}
+
+		return block, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("JSON.parse: unexpected value %v", value))
+}
+
+// Returns true if the object key can be an attribute of a block.
+// It must be an identifier, so it can be used, and must not be
+// asString or call, which change how the block behaves.
+func isAttributeName(key string) bool {
+	if key == "" || key == "asString" || key == "call" {
+		return false
+	}
+
+	tokens := tokeniser.Tokenise(key)
+	return len(tokens) == 1 && tokens[0].Type == "identifier" && tokens[0].Value == key
+}
+
+// Returns the value as JSON, nesting with the indent if it is not empty.
+// Blocks and records become objects of their attributes and lists become arrays.
+// Functions cannot be converted, nor can blocks that contain themselves.
+func ToJSON(value fnScope, indent string) (string, error) {
+	converted, err := jsonValue(value, map[uintptr]bool{})
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(converted); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// Returns the value as a Go value that encoding/json can encode.
+// Blocks being converted are kept in inProgress, to find blocks that contain themselves.
+func jsonValue(value fnScope, inProgress map[uintptr]bool) (interface{}, error) {
+	switch value.(type) {
+	case nil, nothing:
+		return nil, nil
+
+	case *thunk:
+		forced, err := value.(*thunk).force()
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonValue(forced, inProgress)
+
+	case number:
+		// Numbers keep their text where it is valid JSON, so no precision is lost.
+		text := value.(number).value
+		if !json.Valid([]byte(text)) {
+			text = strconv.FormatFloat(value.(number).AsFloat(), 'f', -1, 64)
+		}
+
+		return json.Number(text), nil
+
+	case fnString:
+		return value.(fnString).value, nil
+
+	case fnBool:
+		return value.(fnBool).value, nil
+
+	case list:
+		items := []interface{}{}
+		for _, item := range value.(list).Items {
+			converted, err := jsonValue(item, inProgress)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, converted)
+		}
+
+		return items, nil
+
+	case record:
+		return jsonObject(value.(record).values, inProgress)
+
+	case Scope:
+		id := identity(value.(Scope).definitions)
+		if inProgress[id] {
+			return nil, errors.New("Cannot convert a block that contains itself to JSON")
+		}
+
+		inProgress[id] = true
+		defer delete(inProgress, id)
+
+		return jsonObject(value.(Scope).attributes(), inProgress)
+	}
+
+	return nil, errors.New(fmt.Sprintf("Cannot convert %s to JSON", value.String()))
+}
+
+func jsonObject(definitions defMap, inProgress map[uintptr]bool) (interface{}, error) {
+	object := map[string]interface{}{}
+	for id, value := range definitions {
+		converted, err := jsonValue(value, inProgress)
+		if err != nil {
+			return nil, err
+		}
+
+		object[id] = converted
+	}
+
+	return object, nil
+}
@@ -12,10 +12,7 @@ type list struct {
 }
 
 func (list list) Definitions() defMap {
-	allDefs := defMap{
-		"each":     fn([]string{"fn"}, list.each),
-		"asString": fn([]string{}, list.asString),
-	}
+	allDefs := list.attributes()
 
 	for key, value := range DefaultScope().Definitions() {
 
@@ -28,6 +25,15 @@ func (list list) Definitions() defMap {
 	return allDefs
 }
 
+// Returns the definitions of the list itself,
+// without those of the default scope.
+func (list list) attributes() defMap {
+	return defMap{
+		"each":     fn([]string{"fn"}, list.each),
+		"asString": fn([]string{}, list.asString),
+	}
+}
+
 func (list list) Define(id string, value fnScope) (fnScope, error) {
 	panic("Attempted defining " + id + " on a list!")
 }
@@ -49,9 +55,13 @@ func (list list) String() string {
 }
 
 func (list list) Call(args []fnScope) (fnScope, error) {
+	if err := rejectNamedArgs(args, "List"); err != nil {
+		return nil, err
+	}
+
 	if len(args) != 1 {
 		return nil, errors.New(fmt.Sprintf(
-			"Argument number mismatch: got %i, need 1",
+			"Argument number mismatch: got %d, need 1",
 			len(args),
 		))
 	}
@@ -65,7 +75,15 @@ func (list list) Value() interface{} {
 }
 
 func List(values []fnScope) (fnScope, error) {
-	return list{Items: values}, nil
+	return newList(values), nil
+}
+
+// Returns a list of the values with an array of items of its own,
+// even if it is empty, so that it is only the same as itself.
+func newList(values []fnScope) list {
+	items := make([]fnScope, len(values), len(values)+1)
+	copy(items, values)
+	return list{Items: items}
 }
 
 func (list list) each(args []fnScope) (fnScope, error) {
@@ -76,7 +94,7 @@ func (list list) each(args []fnScope) (fnScope, error) {
 		}
 	}
 
-	return nil, nil
+	return Nothing, nil
 }
 
 func (list list) asString(args []fnScope) (fnScope, error) {
@@ -98,6 +116,10 @@ func (list fnList) String() string {
 }
 
 func (list fnList) Call(args []fnScope) (fnScope, error) {
+	if err := rejectNamedArgs(args, "List"); err != nil {
+		return nil, err
+	}
+
 	return List(args)
 }
 
@@ -0,0 +1,38 @@
+package runtime
+
+import (
+	"errors"
+)
+
+// nothing is the scope representing the absence of a value.
+// It is available in fn as `nil`.
+type nothing struct{}
+
+// The only nothing value.
+var Nothing = nothing{}
+
+func (n nothing) Definitions() defMap {
+	return defMap{
+		"asString": fn([]string{}, n.asString),
+	}
+}
+
+func (n nothing) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on nil!")
+}
+
+func (n nothing) String() string {
+	return "nil"
+}
+
+func (n nothing) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("nil called as a function!")
+}
+
+func (n nothing) Value() interface{} {
+	return nil
+}
+
+func (n nothing) asString(args []fnScope) (fnScope, error) {
+	return FnString(n.String()), nil
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 )
 
@@ -16,8 +17,6 @@ func (num number) Definitions() defMap {
 		"-":        fn([]string{"other"}, num.subtract),
 		"*":        fn([]string{"other"}, num.multiply),
 		"/":        fn([]string{"other"}, num.divide),
-		"and":      fn([]string{"other"}, num.and),
-		"or":       fn([]string{"other"}, num.or),
 		"eq":       fn([]string{"other"}, num.eq),
 		"moreThan": fn([]string{"other"}, num.moreThan),
 		"lessThan": fn([]string{"other"}, num.lessThan),
@@ -68,39 +67,71 @@ func NumberFromFloat(num float64) number {
 }
 
 func (num number) add(args []fnScope) (fnScope, error) {
-	return NumberFromFloat(num.AsFloat() + args[0].(number).AsFloat()), nil
+	other, err := num.operand(args[0], "+")
+	if err != nil {
+		return nil, err
+	}
+
+	return NumberFromFloat(num.AsFloat() + other.AsFloat()), nil
 }
 
 func (num number) subtract(args []fnScope) (fnScope, error) {
-	return NumberFromFloat(num.AsFloat() - args[0].(number).AsFloat()), nil
+	other, err := num.operand(args[0], "-")
+	if err != nil {
+		return nil, err
+	}
+
+	return NumberFromFloat(num.AsFloat() - other.AsFloat()), nil
 }
 
 func (num number) multiply(args []fnScope) (fnScope, error) {
-	return NumberFromFloat(num.AsFloat() * args[0].(number).AsFloat()), nil
+	other, err := num.operand(args[0], "*")
+	if err != nil {
+		return nil, err
+	}
+
+	return NumberFromFloat(num.AsFloat() * other.AsFloat()), nil
 }
 
 func (num number) divide(args []fnScope) (fnScope, error) {
-	return NumberFromFloat(num.AsFloat() / args[0].(number).AsFloat()), nil
+	other, err := num.operand(args[0], "/")
+	if err != nil {
+		return nil, err
+	}
+
+	return NumberFromFloat(num.AsFloat() / other.AsFloat()), nil
 }
 
 func (num number) moreThan(args []fnScope) (fnScope, error) {
-	return FnBool(num.AsFloat() > args[0].(number).AsFloat()), nil
+	other, err := num.operand(args[0], "moreThan")
+	if err != nil {
+		return nil, err
+	}
+
+	return FnBool(num.AsFloat() > other.AsFloat()), nil
 }
 
 func (num number) lessThan(args []fnScope) (fnScope, error) {
-	return FnBool(num.AsFloat() < args[0].(number).AsFloat()), nil
-}
+	other, err := num.operand(args[0], "lessThan")
+	if err != nil {
+		return nil, err
+	}
 
-func (self number) and(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(self) && AsBool(args[0])), nil
+	return FnBool(num.AsFloat() < other.AsFloat()), nil
 }
 
-func (self number) or(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(self) || AsBool(args[0])), nil
+// Returns the other operand of an operator, which must be a number.
+func (num number) operand(other fnScope, op string) (number, error) {
+	otherNumber, ok := other.(number)
+	if !ok {
+		return number{}, errors.New(fmt.Sprintf("Cannot use %s on %s and %s", op, num.String(), other.String()))
+	}
+
+	return otherNumber, nil
 }
 
 func (self number) eq(args []fnScope) (fnScope, error) {
-	return FnBool(self.Value() == args[0].Value()), nil
+	return FnBool(equal(self, args[0])), nil
 }
 
 func (self number) asString(args []fnScope) (fnScope, error) {
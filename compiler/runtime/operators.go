@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Returns the attribute of the value with the given name, or nil.
+// Blocks only provide their own attributes and those of the blocks they extend,
+// so operators are never found in the enclosing scope.
+func attribute(value fnScope, name string) fnScope {
+	switch value.(type) {
+	case Scope:
+		return value.(Scope).attributes()[name]
+	case list:
+		return value.(list).attributes()[name]
+	}
+
+	return value.Definitions()[name]
+}
+
+// Returns a function calling the operator defined by the first argument
+// with the remaining arguments.
+func callOnFirstArgument(op string) fnFunc {
+	return func(args []fnScope) (fnScope, error) {
+		method := attribute(args[0], op)
+		if method == nil {
+			return nil, notImplemented(args[0], op)
+		}
+
+		return method.Call(args[1:])
+	}
+}
+
+// Returns an error for a value that does not implement an operator.
+func notImplemented(value fnScope, op string) error {
+	return errors.New(fmt.Sprintf("%s does not implement %s", value.String(), op))
+}
+
+// Blocks that define eq decide their own equality;
+// all other values are compared structurally.
+func eq(args []fnScope) (fnScope, error) {
+	if _, ok := args[0].(Scope); ok {
+		if method := attribute(args[0], "eq"); method != nil {
+			result, err := method.Call(args[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			return FnBool(AsBool(result)), nil
+		}
+	}
+
+	return FnBool(equal(args[0], args[1])), nil
+}
+
+// Blocks that only define lessThan can be compared with moreThan,
+// as `a moreThan b` is the same as `b lessThan a`.
+func moreThan(args []fnScope) (fnScope, error) {
+	if method := attribute(args[0], "moreThan"); method != nil {
+		return method.Call(args[1:])
+	}
+
+	if method := attribute(args[1], "lessThan"); method != nil {
+		return method.Call(args[:1])
+	}
+
+	return nil, notImplemented(args[0], "moreThan")
+}
+
+// Returns the string representation of the value.
+func asString(args []fnScope) (fnScope, error) {
+	return FnString(args[0].String()), nil
+}
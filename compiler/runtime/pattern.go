@@ -0,0 +1,85 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// Binds the value to the pattern, defining each name in the scope.
+// Returns the scope with the definitions applied.
+func bindPattern(pattern Expression, value fnScope, scope fnScope) (fnScope, error) {
+	switch pattern.(type) {
+	case IdentifierExpression:
+		return scope.Define(pattern.(IdentifierExpression).Name, value)
+	case ListPatternExpression:
+		return bindListPattern(pattern.(ListPatternExpression), value, scope)
+	case BlockPatternExpression:
+		return bindBlockPattern(pattern.(BlockPatternExpression), value, scope)
+	case TypedPatternExpression:
+		// Type annotations are only used by the checker.
+		return bindPattern(pattern.(TypedPatternExpression).Pattern, value, scope)
+	}
+
+	return scope, errors.New(fmt.Sprintf("Cannot assign to %s", pattern.String()))
+}
+
+// Binds each item of a list to the matching item of the pattern.
+func bindListPattern(pattern ListPatternExpression, value fnScope, scope fnScope) (fnScope, error) {
+	valueList, ok := value.(list)
+	if !ok {
+		return scope, errors.New(fmt.Sprintf(
+			"Cannot destructure %s into %s: not a list",
+			value.String(),
+			pattern.String(),
+		))
+	}
+
+	if len(valueList.Items) != len(pattern.Items) {
+		return scope, errors.New(fmt.Sprintf(
+			"Cannot destructure %s into %s: got %d items, need %d",
+			value.String(),
+			pattern.String(),
+			len(valueList.Items),
+			len(pattern.Items),
+		))
+	}
+
+	var err error
+	for idx, item := range pattern.Items {
+		scope, err = bindPattern(item, valueList.Items[idx], scope)
+		if err != nil {
+			return scope, err
+		}
+	}
+
+	return scope, nil
+}
+
+// Binds each name in the pattern to the definition of the same name in the value.
+func bindBlockPattern(pattern BlockPatternExpression, value fnScope, scope fnScope) (fnScope, error) {
+	definitions := value.Definitions()
+	if block, ok := value.(Scope); ok {
+		// Only the block's own attributes can be destructured.
+		definitions = block.attributes()
+	}
+
+	var err error
+	for _, name := range pattern.Names {
+		item := definitions[name.Name]
+		if item == nil {
+			return scope, errors.New(fmt.Sprintf(
+				"Cannot destructure %s: %s is not defined",
+				pattern.String(),
+				name.Name,
+			))
+		}
+
+		scope, err = scope.Define(name.Name, item)
+		if err != nil {
+			return scope, err
+		}
+	}
+
+	return scope, nil
+}
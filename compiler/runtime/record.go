@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// A recordType is created by `Record(name, fields)`.
+// Calling it constructs a record with the given fields.
+type recordType struct {
+	Name        string
+	Fields      argNames
+	constructor functionScope
+	id          uint64   // Identifies the type, so records of different types are not equal.
+	Variants    argNames // The names of all variants of the union, if the type is a variant.
+}
+
+// The id of the most recently created record type.
+var lastRecordTypeId uint64
+
+func (rt recordType) Definitions() defMap {
+	return defMap{
+		"is":       fn([]string{"value"}, rt.is),
+		"asString": fn([]string{}, rt.asString),
+	}
+}
+
+func (rt recordType) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New(fmt.Sprintf("Attempted definition on the %s record type!", rt.Name))
+}
+
+func (rt recordType) String() string {
+	return fmt.Sprintf("Record %s%s", rt.Name, rt.Fields.String())
+}
+
+// Constructs a record.
+// Fields may be given by position or by name.
+func (rt recordType) Call(args []fnScope) (fnScope, error) {
+	return rt.constructor.Call(args)
+}
+
+func (rt recordType) Value() interface{} {
+	return rt.id
+}
+
+// Returns true if the value is a record of this type.
+func (rt recordType) is(args []fnScope) (fnScope, error) {
+	rec, ok := args[0].(record)
+	return FnBool(ok && rec.recordType.id == rt.id), nil
+}
+
+func (rt recordType) asString(args []fnScope) (fnScope, error) {
+	return FnString(rt.String()), nil
+}
+
+// A record is an immutable scope holding only the fields of its type.
+type record struct {
+	recordType recordType
+	values     defMap
+}
+
+func (rec record) Definitions() defMap {
+	allDefs := defMap{
+		"asString": fn([]string{}, rec.asString),
+		"eq":       fn([]string{"other"}, rec.eq),
+	}
+
+	for id, value := range rec.values {
+		allDefs[id] = value
+	}
+
+	return allDefs
+}
+
+func (rec record) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New(fmt.Sprintf(
+		"Cannot define %s on %s: records cannot be changed",
+		id,
+		rec.String(),
+	))
+}
+
+// Returns the record in the form `Point(x: 1, y: 2)`,
+// or just the name for variants without fields.
+func (rec record) String() string {
+	if len(rec.recordType.Fields) == 0 && rec.recordType.Variants != nil {
+		return rec.recordType.Name
+	}
+
+	var str bytes.Buffer
+	str.WriteString(rec.recordType.Name + "(")
+
+	for idx, field := range rec.recordType.Fields {
+		if idx > 0 {
+			str.WriteString(", ")
+		}
+
+		str.WriteString(field + ": " + rec.values[field].String())
+	}
+
+	str.WriteString(")")
+	return str.String()
+}
+
+func (rec record) Call(args []fnScope) (fnScope, error) {
+	return nil, notImplemented(rec, "call")
+}
+
+func (rec record) Value() interface{} {
+	return rec.values
+}
+
+func (rec record) asString(args []fnScope) (fnScope, error) {
+	return FnString(rec.String()), nil
+}
+
+func (rec record) eq(args []fnScope) (fnScope, error) {
+	return FnBool(equal(rec, args[0])), nil
+}
+
+// Returns a copy of the record with the given fields changed.
+func (rec record) with(changes defMap) (fnScope, error) {
+	values := defMap{}
+	for id, value := range rec.values {
+		values[id] = value
+	}
+
+	for id, value := range changes {
+		if rec.recordType.Fields.indexOf(id) == -1 {
+			return nil, errors.New(fmt.Sprintf("%s has no field named %s", rec.recordType.Name, id))
+		}
+
+		values[id] = value
+	}
+
+	return record{recordType: rec.recordType, values: values}, nil
+}
+
+// Creates a record type from a name and a list of field names.
+func fnRecord(args []fnScope) (fnScope, error) {
+	name, ok := args[0].(fnString)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Record name must be a string, got %s", args[0].String()))
+	}
+
+	fieldList, ok := args[1].(list)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Record fields must be a list, got %s", args[1].String()))
+	}
+
+	fields := argNames{}
+	for _, item := range fieldList.Items {
+		field, ok := item.(fnString)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Record field names must be strings, got %s", item.String()))
+		}
+
+		if fields.indexOf(field.value) != -1 {
+			return nil, errors.New(fmt.Sprintf("Record %s has more than one field named %s", name.value, field.value))
+		}
+
+		fields = append(fields, field.value)
+	}
+
+	return newRecordType(name.value, fields, nil), nil
+}
+
+// Creates a record type with the given fields.
+// Variants are the names of all variants of the union the type belongs to, if any.
+func newRecordType(name string, fields argNames, variants argNames) recordType {
+	rt := recordType{
+		Name:     name,
+		Fields:   fields,
+		id:       atomic.AddUint64(&lastRecordTypeId, 1),
+		Variants: variants,
+	}
+
+	rt.constructor = fn(fields, func(values []fnScope) (fnScope, error) {
+		rec := record{recordType: rt, values: defMap{}}
+		for idx, field := range fields {
+			rec.values[field] = values[idx]
+		}
+
+		return rec, nil
+	}).named(name)
+
+	return rt
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 )
 
 type defMap map[string]fnScope
@@ -30,10 +31,17 @@ type fnScope interface {
 type Scope struct {
 	parent      *fnScope
 	definitions defMap
+	proto       *Scope // The block this block extends, if any.
+
+	// If set, definitions can be replaced, calling this with the name.
+	// Only the REPL allows this.
+	redefined func(id string)
 }
 
 func (scope Scope) Definitions() defMap {
-	allDefs := scope.definitions
+	// Copy the definitions, so the parent definitions
+	// are not added to this scope.
+	allDefs := scope.attributes()
 
 	if scope.parent != nil {
 		for key, value := range (*scope.parent).Definitions() {
@@ -48,9 +56,44 @@ func (scope Scope) Definitions() defMap {
 	return allDefs
 }
 
+// Returns the definitions of this block and the blocks it extends,
+// without those of its parent.
+func (scope Scope) attributes() defMap {
+	attributes := defMap{}
+	for key, value := range scope.definitions {
+		attributes[key] = value
+	}
+
+	if scope.proto != nil {
+		for key, value := range scope.proto.attributes() {
+			_, ok := attributes[key]
+			if !ok {
+				attributes[key] = value
+			}
+		}
+	}
+
+	return attributes
+}
+
+// Returns the names of the attributes of this block, sorted.
+func (scope Scope) Names() []string {
+	names := []string{}
+	for id := range scope.attributes() {
+		names = append(names, id)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
 func (scope Scope) Define(id string, value fnScope) (fnScope, error) {
 	if scope.definitions[id] != nil {
-		return scope, errors.New(fmt.Sprintf("%s is already defined!", id))
+		if scope.redefined == nil {
+			return scope, errors.New(fmt.Sprintf("%s is already defined!", id))
+		}
+
+		scope.redefined(id)
 	}
 
 	scope.definitions[id] = value
@@ -58,6 +101,13 @@ func (scope Scope) Define(id string, value fnScope) (fnScope, error) {
 }
 
 func (scope Scope) String() string {
+	if method := scope.attributes()["asString"]; method != nil {
+		str, err := method.Call([]fnScope{})
+		if err == nil {
+			return str.String()
+		}
+	}
+
 	if scope.definitions["value"] != nil {
 		return scope.definitions["value"].String()
 	} else {
@@ -77,11 +127,11 @@ func (scope Scope) String() string {
 }
 
 func (scope Scope) Call(args []fnScope) (fnScope, error) {
-	if scope.definitions["call"] != nil {
-		return scope.definitions["call"].Call(args)
+	if method := scope.attributes()["call"]; method != nil {
+		return method.Call(args)
 	}
 
-	return nil, errors.New("Scope cannot be called")
+	return nil, notImplemented(scope, "call")
 }
 
 // Scopes are unique.
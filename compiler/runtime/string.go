@@ -12,8 +12,6 @@ type fnString struct {
 func (str fnString) Definitions() defMap {
 	return defMap{
 		"eq":       fn([]string{"other"}, str.eq),
-		"and":      fn([]string{"other"}, str.and),
-		"or":       fn([]string{"other"}, str.or),
 		"asString": fn([]string{}, str.asString),
 	}
 }
@@ -38,16 +36,8 @@ func FnString(str string) fnString {
 	return fnString{value: str}
 }
 
-func (self fnString) and(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(self) && AsBool(args[0])), nil
-}
-
-func (self fnString) or(args []fnScope) (fnScope, error) {
-	return FnBool(AsBool(self) || AsBool(args[0])), nil
-}
-
 func (self fnString) eq(args []fnScope) (fnScope, error) {
-	return FnBool(self.Value() == args[0].Value()), nil
+	return FnBool(equal(self, args[0])), nil
 }
 
 func (self fnString) asString(args []fnScope) (fnScope, error) {
@@ -0,0 +1,77 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+)
+
+// The arguments given to the program after its file name.
+// Built once by SetArgs, so System.args is always the same list.
+var systemArgs = newList([]fnScope{})
+
+// The directory the program started in.
+var systemCwd = workingDirectory()
+
+// Sets the arguments given to the program after its file name.
+// Called by the CLI before running.
+func SetArgs(values []string) {
+	items := []fnScope{}
+	for _, value := range values {
+		items = append(items, FnString(value))
+	}
+
+	systemArgs = newList(items)
+}
+
+func workingDirectory() fnScope {
+	dir, err := os.Getwd()
+	if err != nil {
+		return FnString("")
+	}
+
+	return FnString(dir)
+}
+
+// system is the scope holding what a program knows about where it runs.
+// It is available in fn as `System`.
+type system struct{}
+
+func (s system) Definitions() defMap {
+	return defMap{
+		"args":     systemArgs,
+		"cwd":      systemCwd,
+		"env":      fn([]string{"name"}, s.env).named("env"),
+		"exit":     fn([]string{"code"}, fnExit).named("exit"),
+		"asString": fn([]string{}, s.asString),
+	}
+}
+
+func (s system) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on System!")
+}
+
+func (s system) String() string {
+	return "System"
+}
+
+func (s system) Call(args []fnScope) (fnScope, error) {
+	return nil, errors.New("System called as a function!")
+}
+
+func (s system) Value() interface{} {
+	return s
+}
+
+// Returns the environment variable with the name, or nil if it is not set.
+func (s system) env(args []fnScope) (fnScope, error) {
+	value, ok := os.LookupEnv(args[0].String())
+	if !ok {
+		return Nothing, nil
+	}
+
+	return FnString(value), nil
+}
+
+func (s system) asString(args []fnScope) (fnScope, error) {
+	return FnString(s.String()), nil
+}
@@ -0,0 +1,78 @@
+package runtime
+
+import (
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// A thunk is an argument whose evaluation is delayed until it is used.
+// It is evaluated at most once.
+//
+// Thunks are forced when their name is looked up, which reports any error,
+// so they do not reach String, Value or Definitions unless forced.
+type thunk struct {
+	expr  Expression
+	scope fnScope
+
+	forced bool
+	value  fnScope
+	err    error
+}
+
+func newThunk(expr Expression, scope fnScope) *thunk {
+	return &thunk{expr: expr, scope: scope}
+}
+
+// Evaluates the thunk, if it has not been already.
+func (t *thunk) force() (fnScope, error) {
+	if !t.forced {
+		result := exec(t.expr, t.scope)
+		t.value, t.err, t.forced = result.Value, result.Error, true
+	}
+
+	return t.value, t.err
+}
+
+func (t *thunk) Definitions() defMap {
+	value, err := t.force()
+	if err != nil || value == nil {
+		return defMap{}
+	}
+
+	return value.Definitions()
+}
+
+func (t *thunk) Define(id string, value fnScope) (fnScope, error) {
+	forced, err := t.force()
+	if err != nil {
+		return nil, err
+	}
+
+	return forced.Define(id, value)
+}
+
+func (t *thunk) String() string {
+	value, err := t.force()
+	if err != nil {
+		return err.Error()
+	}
+
+	return value.String()
+}
+
+func (t *thunk) Call(args []fnScope) (fnScope, error) {
+	value, err := t.force()
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Call(args)
+}
+
+func (t *thunk) Value() interface{} {
+	value, err := t.force()
+	if err != nil || value == nil {
+		return nil
+	}
+
+	return value.Value()
+}
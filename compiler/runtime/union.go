@@ -0,0 +1,144 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+)
+
+// A unionType is created by `Union(Circle(r), Square(side))`.
+// Each variant is a record type; variants without fields are single records.
+type unionType struct {
+	VariantNames argNames
+	variants     defMap // Constructors (or records, for variants without fields), by name.
+}
+
+func (ut unionType) Definitions() defMap {
+	allDefs := defMap{
+		"is":       fn([]string{"value"}, ut.is),
+		"asString": fn([]string{}, ut.asString),
+	}
+
+	for name, variant := range ut.variants {
+		allDefs[name] = variant
+	}
+
+	return allDefs
+}
+
+func (ut unionType) Define(id string, value fnScope) (fnScope, error) {
+	return nil, errors.New("Attempted definition on a union!")
+}
+
+func (ut unionType) String() string {
+	variants := []string{}
+	for _, name := range ut.VariantNames {
+		variants = append(variants, ut.variants[name].String())
+	}
+
+	return fmt.Sprintf("Union(%s)", strings.Join(variants, ", "))
+}
+
+func (ut unionType) Call(args []fnScope) (fnScope, error) {
+	return nil, notImplemented(ut, "call")
+}
+
+func (ut unionType) Value() interface{} {
+	return ut.VariantNames.String()
+}
+
+func (ut unionType) asString(args []fnScope) (fnScope, error) {
+	return FnString(ut.String()), nil
+}
+
+// Returns true if the value is a variant of this union.
+func (ut unionType) is(args []fnScope) (fnScope, error) {
+	rec, ok := args[0].(record)
+	if !ok {
+		return FnBool(false), nil
+	}
+
+	variantType, ok := ut.typeOf(rec.recordType.Name)
+	return FnBool(ok && variantType.id == rec.recordType.id), nil
+}
+
+// Returns the record type of the named variant.
+func (ut unionType) typeOf(name string) (recordType, bool) {
+	switch ut.variants[name].(type) {
+	case recordType:
+		return ut.variants[name].(recordType), true
+	case record:
+		return ut.variants[name].(record).recordType, true
+	}
+
+	return recordType{}, false
+}
+
+// Execute a `Union` function call.
+// The arguments are not executed, but describe the variants:
+// `Circle(r)` is a variant with a field r, and `None` a variant without fields.
+// Each variant is also defined in the scope.
+func execUnion(args []Expression, scope fnScope) EvalResult {
+	names := argNames{}
+	fieldsByName := map[string]argNames{}
+
+	for _, arg := range args {
+		var (
+			name   string
+			fields = argNames{}
+		)
+
+		switch arg.(type) {
+		case IdentifierExpression:
+			name = arg.(IdentifierExpression).Name
+
+		case FunctionCallExpression:
+			call := arg.(FunctionCallExpression)
+			name = call.Identifier.Name
+
+			for _, fieldArg := range call.Arguments {
+				field, ok := fieldArg.(IdentifierExpression)
+				if !ok {
+					return EvalResult{Error: errors.New(fmt.Sprintf("Expected a field name in %s, found %s", arg.String(), fieldArg.String()))}
+				}
+
+				if fields.indexOf(field.Name) != -1 {
+					return EvalResult{Error: errors.New(fmt.Sprintf("Variant %s has more than one field named %s", name, field.Name))}
+				}
+
+				fields = append(fields, field.Name)
+			}
+
+		default:
+			return EvalResult{Error: errors.New(fmt.Sprintf("Expected a variant such as Name(field), found %s", arg.String()))}
+		}
+
+		if names.indexOf(name) != -1 {
+			return EvalResult{Error: errors.New(fmt.Sprintf("Union has more than one variant named %s", name))}
+		}
+
+		names = append(names, name)
+		fieldsByName[name] = fields
+	}
+
+	union := unionType{VariantNames: names, variants: defMap{}}
+	for _, name := range names {
+		variantType := newRecordType(name, fieldsByName[name], names)
+
+		if len(fieldsByName[name]) == 0 {
+			union.variants[name] = record{recordType: variantType, values: defMap{}}
+		} else {
+			union.variants[name] = variantType
+		}
+
+		var err error
+		scope, err = scope.Define(name, union.variants[name])
+		if err != nil {
+			return EvalResult{Error: err}
+		}
+	}
+
+	return EvalResult{Value: union, Scope: scope}
+}
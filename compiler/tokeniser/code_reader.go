@@ -36,6 +36,12 @@ func (reader CodeReader) Next() rune {
 	return r
 }
 
+// Returns true if the code starts with the given prefix.
+// Does not advance the code pointer.
+func (reader CodeReader) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(reader.code, prefix)
+}
+
 // Advances to the next rune.
 // Returns the rune that was initially pointed to.
 func (reader *CodeReader) Pop() rune {
@@ -22,6 +22,9 @@ func Tokenise(input string) []Token {
 	return tokens
 }
 
+// The characters that end an identifier.
+const IdentifierDelimiters = " \r\n#\"(){},:;.=+-/*"
+
 // A symbol tokeniser works on the first token of the code.
 // They run before identifier tokenisers,
 // and require the CodeReader object for eating.
@@ -42,6 +45,8 @@ func nextToken(code *CodeReader) Token {
 		tryBasicTokens,
 		tryString,
 		tryNumber,
+		tryEllipsis,
+		tryArrow,
 		trySymbolInfixOperator,
 	}
 
@@ -64,7 +69,7 @@ func nextToken(code *CodeReader) Token {
 		}
 
 		// Identifier/keyword
-		id := code.EatUntil(" \r\n#\"(){},;.=+-/*")
+		id := code.EatUntil(IdentifierDelimiters)
 		if id == "" {
 			panic("Empty identifier!")
 		}
@@ -62,6 +62,49 @@ func TestTokeniser(t *testing.T) {
 		})
 	})
 
+	Convey("Colon is found", t, func() {
+		SoCodeYieldsTokens("a: b", []Token{
+			Token{Type: "identifier", Value: "a"},
+			Token{Type: "colon"},
+			Token{Type: "identifier", Value: "b"},
+		})
+	})
+
+	Convey("Ellipsis", t, func() {
+		Convey("is found before an identifier", func() {
+			SoCodeYieldsTokens("...rest", []Token{
+				Token{Type: "ellipsis"},
+				Token{Type: "identifier", Value: "rest"},
+			})
+		})
+
+		Convey("is not confused with the dereference operator", func() {
+			SoCodeYieldsTokens("a.b", []Token{
+				Token{Type: "identifier", Value: "a"},
+				Token{Type: "infix_operator", Value: "."},
+				Token{Type: "identifier", Value: "b"},
+			})
+		})
+	})
+
+	Convey("Arrow", t, func() {
+		Convey("is found before a type", func() {
+			SoCodeYieldsTokens(") -> Number", []Token{
+				Token{Type: "bracket_close"},
+				Token{Type: "arrow"},
+				Token{Type: "identifier", Value: "Number"},
+			})
+		})
+
+		Convey("is not confused with the minus operator", func() {
+			SoCodeYieldsTokens("a - b", []Token{
+				Token{Type: "identifier", Value: "a"},
+				Token{Type: "infix_operator", Value: "-"},
+				Token{Type: "identifier", Value: "b"},
+			})
+		})
+	})
+
 	Convey("End statement is found", t, func() {
 		SoCodeYieldsTokens(";", []Token{
 			Token{Type: "end_statement"},
@@ -76,6 +119,19 @@ func TestTokeniser(t *testing.T) {
 		})
 	})
 
+	Convey("Extend is its own token", t, func() {
+		SoCodeYieldsTokens("extend extender", []Token{
+			Token{Type: "extend"},
+			Token{Type: "identifier", Value: "extender"},
+		})
+	})
+
+	Convey("Reserved words are not identifiers", t, func() {
+		for _, word := range ReservedWords() {
+			So(Tokenise(word)[0].Type, ShouldNotEqual, "identifier")
+		}
+	})
+
 	Convey("Infix operators", t, func() {
 		Convey("are found with spaces around them", func() {
 			SoCodeYieldsTokens("a eq b", []Token{
@@ -85,6 +141,22 @@ func TestTokeniser(t *testing.T) {
 			})
 		})
 
+		Convey("include the pipeline operator", func() {
+			SoCodeYieldsTokens("a |> b", []Token{
+				Token{Type: "identifier", Value: "a"},
+				Token{Type: "infix_operator", Value: "|>"},
+				Token{Type: "identifier", Value: "b"},
+			})
+		})
+
+		Convey("include with", func() {
+			SoCodeYieldsTokens("a with b", []Token{
+				Token{Type: "identifier", Value: "a"},
+				Token{Type: "infix_operator", Value: "with"},
+				Token{Type: "identifier", Value: "b"},
+			})
+		})
+
 		Convey("without symbols are not found inside identifiers", func() {
 			SoCodeYieldsTokens("aeqb", []Token{
 				Token{Type: "identifier", Value: "aeqb"},
@@ -201,6 +273,14 @@ func TestTokeniser(t *testing.T) {
 			})
 		})
 
+		Convey("can not include a colon", func() {
+			SoCodeYieldsTokens("ab:cd", []Token{
+				Token{Type: "identifier", Value: "ab"},
+				Token{Type: "colon"},
+				Token{Type: "identifier", Value: "cd"},
+			})
+		})
+
 		Convey("can not include an end statement", func() {
 			SoCodeYieldsTokens("ab;cd", []Token{
 				Token{Type: "identifier", Value: "ab"},
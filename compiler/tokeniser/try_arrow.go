@@ -0,0 +1,21 @@
+package tokeniser
+
+var arrow = "->"
+
+// Arrows mark return types, e.g. `(a: Number) -> Number { a }`.
+// They are checked before symbol infix operators, as they start with `-`
+func tryArrow(code *CodeReader) *Token {
+	if !code.HasPrefix(arrow) {
+		return nil
+	}
+
+	token := Token{
+		Type: "arrow",
+	}
+
+	for range arrow {
+		code.Pop() // Eat arrow
+	}
+
+	return &token
+}
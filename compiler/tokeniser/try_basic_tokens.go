@@ -4,6 +4,7 @@ var basicTokens = map[rune]string{
 	'(': "bracket_open",
 	')': "bracket_close",
 	',': "comma",
+	':': "colon",
 	';': "end_statement",
 	'{': "block_open",
 	'}': "block_close",
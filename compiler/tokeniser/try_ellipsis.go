@@ -0,0 +1,21 @@
+package tokeniser
+
+var ellipsis = "..."
+
+// Ellipses mark rest arguments, e.g. `(first, ...rest) { rest }`.
+// They are checked before symbol infix operators, as they start with `.`
+func tryEllipsis(code *CodeReader) *Token {
+	if !code.HasPrefix(ellipsis) {
+		return nil
+	}
+
+	token := Token{
+		Type: "ellipsis",
+	}
+
+	for range ellipsis {
+		code.Pop() // Eat ellipsis
+	}
+
+	return &token
+}
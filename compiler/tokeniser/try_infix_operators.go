@@ -5,7 +5,7 @@ import (
 )
 
 var symbolInfixOperators = "+-/*.="
-var stringInfixOperators = []string{"eq", "and", "or", "moreThan", "lessThan"}
+var stringInfixOperators = []string{"eq", "and", "or", "moreThan", "lessThan", "|>", "with"}
 
 func trySymbolInfixOperator(code *CodeReader) *Token {
 	if !strings.ContainsRune(symbolInfixOperators, code.Next()) {
@@ -1,6 +1,13 @@
 package tokeniser
 
-var keywords = []string{"when"}
+var keywords = []string{"when", "extend"}
+
+// Returns the words that are not identifiers:
+// keywords, booleans and infix operators such as `eq`.
+func ReservedWords() []string {
+	words := append([]string{"true", "false"}, keywords...)
+	return append(words, stringInfixOperators...)
+}
 
 func tryKeyword(id string) *Token {
 	var (
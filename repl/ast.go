@@ -0,0 +1,57 @@
+package repl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Returns the expression as an indented tree of its fields.
+func dumpAST(expr interface{}) string {
+	return dump(reflect.ValueOf(expr), "")
+}
+
+func dump(value reflect.Value, indent string) string {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return "nil"
+
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return "nil"
+		}
+
+		return dump(value.Elem(), indent)
+
+	case reflect.Struct:
+		tree := value.Type().Name()
+		for idx := 0; idx < value.NumField(); idx++ {
+			field := dump(value.Field(idx), indent+"  ")
+			if !strings.HasPrefix(field, "\n") {
+				field = " " + field
+			}
+
+			tree += "\n" + indent + "  " + value.Type().Field(idx).Name + ":" + field
+		}
+
+		return tree
+
+	case reflect.Slice:
+		if value.Len() == 0 {
+			return "[]"
+		}
+
+		tree := ""
+		for idx := 0; idx < value.Len(); idx++ {
+			tree += "\n" + indent + "  - " + dump(value.Index(idx), indent+"    ")
+		}
+
+		return tree
+
+	case reflect.String:
+		return strconv.Quote(value.String())
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
+)
+
+// A meta-command, such as `:type 1 + 2`.
+// Commands start with a colon, so never clash with fn definitions.
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, arg string)
+}
+
+var commands []command
+
+// Commands are set up here, as :help refers to the list of commands.
+func init() {
+	commands = []command{
+		{"help", ":help", "Lists the commands.", (*session).help},
+		{"type", ":type expr", "Shows the type of the expression without running it.", (*session).showType},
+		{"ast", ":ast expr", "Shows the parsed expression tree.", (*session).showAST},
+		{"tokens", ":tokens expr", "Shows the tokens of the expression.", (*session).showTokens},
+		{"scope", ":scope", "Lists the definitions made so far.", (*session).showScope},
+		{"load", ":load file.fn", "Runs the file in the current scope.", (*session).load},
+		{"reset", ":reset", "Forgets every definition.", (*session).reset},
+		{"time", ":time expr", "Runs the expression and shows how long it took.", (*session).time},
+	}
+}
+
+// Returns true if the input is a meta-command rather than fn code.
+func isCommand(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), ":")
+}
+
+// Runs the meta-command in the input, e.g. `:type 1 + 2`.
+func (s *session) runCommand(text string) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), ":")
+	name, arg := text, ""
+	if idx := strings.IndexAny(text, " \t\n"); idx != -1 {
+		name, arg = text[:idx], strings.TrimSpace(text[idx:])
+	}
+
+	for _, command := range commands {
+		if command.name == name {
+			command.run(s, arg)
+			return
+		}
+	}
+
+	fmt.Printf("Unknown command :%s; try :help\n", name)
+}
+
+func (s *session) help(arg string) {
+	for _, command := range commands {
+		fmt.Printf("  %-15s %s\n", command.usage, command.help)
+	}
+}
+
+func (s *session) showType(arg string) {
+	expressions, err := Parse(Tokenise(arg))
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	// Inferred on a copy, so definitions in arg are not kept.
+	t, errs := s.checker.Copy().Infer(expressions)
+	for _, err := range errs {
+		fmt.Println(err.Error())
+	}
+
+	fmt.Println(t.String())
+}
+
+func (s *session) showAST(arg string) {
+	expressions, err := Parse(Tokenise(arg))
+	for _, expr := range expressions {
+		fmt.Println(dumpAST(expr))
+	}
+
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func (s *session) showTokens(arg string) {
+	for _, token := range Tokenise(arg) {
+		fmt.Println(token.String())
+	}
+}
+
+func (s *session) showScope(arg string) {
+	definitions := s.scope.Definitions()
+	for _, name := range s.scope.Names() {
+		fmt.Printf("%s = %s\n", name, definitions[name].String())
+	}
+}
+
+func (s *session) load(arg string) {
+	file, err := ioutil.ReadFile(arg)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	s.eval(string(file))
+}
+
+func (s *session) reset(arg string) {
+	*s = *newSession()
+	fmt.Println("All definitions forgotten.")
+}
+
+func (s *session) time(arg string) {
+	start := time.Now()
+	s.eval(arg)
+	fmt.Printf("Took %s\n", time.Since(start))
+}
@@ -0,0 +1,149 @@
+package repl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	. "github.com/jonnyarnold/fn-go/compiler/runtime"
+	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
+)
+
+// Matches an unfinished path in an import, e.g. `import("lib/ma`.
+var importPathPattern = regexp.MustCompile(`import!?\("([^"]*)$`)
+
+// Matches the identifiers before a `.`, e.g. `a.b.` in `x = a.b.fo`.
+var attributeChainPattern = regexp.MustCompile(`([^` + regexp.QuoteMeta(IdentifierDelimiters) + `]+\.)+$`)
+
+// Completes the word before the cursor, for liner.
+// Returns the line before the word, the possible words, and the line after the cursor.
+func (s *session) complete(line string, pos int) (string, []string, string) {
+	// liner gives pos in runes, not bytes.
+	runes := []rune(line)
+	before, after := string(runes[:pos]), string(runes[pos:])
+
+	if match := importPathPattern.FindStringSubmatch(before); match != nil {
+		path := match[1]
+		return before[:len(before)-len(path)], completePath(path), after
+	}
+
+	wordStart := strings.LastIndexAny(before, IdentifierDelimiters) + 1
+	head, word := before[:wordStart], before[wordStart:]
+
+	var names []string
+	if strings.HasSuffix(head, ".") {
+		names = s.attributeNames(attributeChainPattern.FindString(head))
+	} else {
+		names = s.identifierNames()
+	}
+
+	completions := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, word) {
+			completions = append(completions, name)
+		}
+	}
+
+	return head, completions, after
+}
+
+// Returns the names defined in the session and the reserved words.
+func (s *session) identifierNames() []string {
+	names := ReservedWords()
+	reserved := map[string]bool{}
+	for _, word := range names {
+		reserved[word] = true
+	}
+
+	// Word operators such as `eq` are also defined as functions.
+	for name := range s.scope.Definitions() {
+		if isIdentifier(name) && !reserved[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Returns the attributes of the value named by the chain, e.g. `a.b.`.
+// The chain is only looked up, never executed, so completion cannot run code.
+func (s *session) attributeNames(chain string) []string {
+	if chain == "" {
+		return []string{}
+	}
+
+	parts := strings.Split(strings.TrimSuffix(chain, "."), ".")
+
+	value, ok := s.scope.Definitions()[parts[0]]
+	for _, part := range parts[1:] {
+		if !ok {
+			break
+		}
+
+		value, ok = value.Definitions()[part]
+	}
+
+	if !ok {
+		return []string{}
+	}
+
+	// Blocks also see the definitions around them, which are not attributes.
+	if block, isBlock := value.(Scope); isBlock {
+		return block.Names()
+	}
+
+	names := []string{}
+	for name := range value.Definitions() {
+		if isIdentifier(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Returns the files and directories starting with the path.
+// Directories end with a slash, so they can be completed into.
+func completePath(path string) []string {
+	dir, prefix := filepath.Split(path)
+
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+
+	entries, err := ioutil.ReadDir(listDir)
+	if err != nil {
+		return []string{}
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		if entry.IsDir() {
+			paths = append(paths, dir+entry.Name()+"/")
+		} else {
+			paths = append(paths, dir+entry.Name())
+		}
+	}
+
+	return paths
+}
+
+// Returns true if the name can be typed as an identifier,
+// rather than being an operator such as `+`.
+func isIdentifier(name string) bool {
+	for _, r := range name {
+		return unicode.IsLetter(r) || r == '_'
+	}
+
+	return false
+}
@@ -0,0 +1,27 @@
+package repl
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	Convey("complete", t, func() {
+		s := newSession()
+		s.eval("foobar = 1")
+
+		Convey("completes the word before the cursor", func() {
+			head, completions, tail := s.complete("x = foob + 1", 8)
+			So(head, ShouldEqual, "x = ")
+			So(completions, ShouldResemble, []string{"foobar"})
+			So(tail, ShouldEqual, " + 1")
+		})
+
+		Convey("counts the cursor position in runes", func() {
+			head, completions, tail := s.complete("x = \"é\" + foob", 14)
+			So(head, ShouldEqual, "x = \"é\" + ")
+			So(completions, ShouldResemble, []string{"foobar"})
+			So(tail, ShouldEqual, "")
+		})
+	})
+}
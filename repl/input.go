@@ -0,0 +1,110 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterh/liner"
+)
+
+// Returns true if every bracket, block and string in the code is closed.
+func isComplete(code string) bool {
+	depth := 0
+	inString, inComment := false, false
+
+	for _, r := range code {
+		switch {
+		case inComment:
+			inComment = r != '\n'
+		case inString:
+			inString = r != '"'
+		case r == '"':
+			inString = true
+		case r == '#':
+			inComment = true
+		case r == '(' || r == '{':
+			depth += 1
+		case r == ')' || r == '}':
+			depth -= 1
+		}
+	}
+
+	return !inString && depth <= 0
+}
+
+// Returns the code on a single line, for the history file.
+// Newlines do not end statements, so lines are joined with spaces
+// once comments are removed.
+// Newlines inside strings cannot be joined without changing the string,
+// so code with them returns "" and is not kept.
+func historyEntry(code string) string {
+	entry := []rune{}
+	inString, inComment := false, false
+
+	for _, r := range code {
+		switch {
+		case inComment:
+			inComment = r != '\n'
+			if inComment {
+				continue
+			}
+		case inString:
+			if r == '\n' || r == '\r' {
+				return ""
+			}
+
+			inString = r != '"'
+		case r == '"':
+			inString = true
+		case r == '#':
+			inComment = true
+			continue
+		}
+
+		if r == '\n' || r == '\r' {
+			r = ' '
+		}
+
+		entry = append(entry, r)
+	}
+
+	return strings.TrimSpace(string(entry))
+}
+
+// Returns the path of the history file, ~/.fn_history.
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".fn_history")
+}
+
+// Loads the history saved by previous sessions, if there is any.
+func loadHistory(line *liner.State) {
+	file, err := os.Open(historyPath())
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+	line.ReadHistory(file)
+}
+
+// Saves the history for future sessions.
+func saveHistory(line *liner.State) {
+	path := historyPath()
+	if path == "" {
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+	line.WriteHistory(file)
+}
@@ -0,0 +1,64 @@
+package repl
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestInput(t *testing.T) {
+	Convey("isComplete", t, func() {
+
+		Convey("is true when every bracket and block is closed", func() {
+			So(isComplete("x = f(1)"), ShouldBeTrue)
+			So(isComplete("f = (a) {\n  a\n}"), ShouldBeTrue)
+		})
+
+		Convey("is false with unbalanced brackets or blocks", func() {
+			So(isComplete("f(1,"), ShouldBeFalse)
+			So(isComplete("f = (a) {"), ShouldBeFalse)
+			So(isComplete("when {\n  true { 1 }"), ShouldBeFalse)
+		})
+
+		Convey("is true with too many closing brackets, so the error is shown", func() {
+			So(isComplete("f(1))"), ShouldBeTrue)
+		})
+
+		Convey("is false inside a string", func() {
+			So(isComplete("x = \"abc"), ShouldBeFalse)
+		})
+
+		Convey("ignores brackets and # inside strings", func() {
+			So(isComplete("x = \"(\""), ShouldBeTrue)
+			So(isComplete("x = \"# {\""), ShouldBeTrue)
+			So(isComplete("f(\"#\""), ShouldBeFalse)
+		})
+
+		Convey("ignores brackets and quotes inside comments", func() {
+			So(isComplete("x = 1 # { ( \""), ShouldBeTrue)
+			So(isComplete("# comment\nf = (a) {"), ShouldBeFalse)
+		})
+
+	})
+
+	Convey("historyEntry", t, func() {
+
+		Convey("joins lines with spaces", func() {
+			So(historyEntry("f = (a) {\n  a\n}"), ShouldEqual, "f = (a) {   a }")
+		})
+
+		Convey("removes comments, which would comment out the lines after them", func() {
+			So(historyEntry("f = (a) { # identity\n  a\n}"), ShouldEqual, "f = (a) {    a }")
+			So(historyEntry("x = 1 # one"), ShouldEqual, "x = 1")
+		})
+
+		Convey("keeps # inside strings", func() {
+			So(historyEntry("print(\"#1\") # a comment"), ShouldEqual, "print(\"#1\")")
+		})
+
+		Convey("is empty for newlines inside strings, which cannot be joined", func() {
+			So(historyEntry("x = \"a\nb\""), ShouldEqual, "")
+			So(historyEntry("s = \"a # b\n\""), ShouldEqual, "")
+		})
+
+	})
+}
@@ -1,38 +1,134 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jonnyarnold/fn-go/compiler/checker"
 	. "github.com/jonnyarnold/fn-go/compiler/parser"
 	. "github.com/jonnyarnold/fn-go/compiler/runtime"
 	. "github.com/jonnyarnold/fn-go/compiler/tokeniser"
-	"os"
+	"github.com/peterh/liner"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
 )
 
 // Starts the REPL and takes control.
-func Run() {
-	reader := bufio.NewReader(os.Stdin)
-	replScope := DefaultScope()
+// Returns the exit code when the input ends (e.g. Ctrl-D) or exit() is called.
+func Run() int {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+	loadHistory(line)
+	defer saveHistory(line)
+
+	session := newSession()
+	line.SetWordCompleter(session.complete)
 
 	for {
-		fmt.Print("> ")
-		text, _ := reader.ReadString('\n')
+		text, err := readInput(line)
+		if err == io.EOF {
+			fmt.Println()
+			return 0
+		}
+
+		if err != nil {
+			// Ctrl-C abandons the current input.
+			continue
+		}
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
 
-		tokens := Tokenise(text)
-		expressions, _ := Parse(tokens)
+		if entry := historyEntry(text); entry != "" {
+			line.AppendHistory(entry)
+		}
 
-		for _, expr := range expressions {
-			fmt.Println(expr)
+		if isCommand(text) {
+			session.runCommand(text)
+		} else {
+			session.eval(text)
 		}
 
-		result := ExecuteIn(expressions, replScope)
+		if session.exit != nil {
+			return session.exit.Code
+		}
+	}
+}
+
+// The definitions made during a REPL session.
+// The checker is kept alongside the scope so :type knows the same names.
+type session struct {
+	scope   Scope
+	checker *checker.Checker
+	exit    *ExitError // Set when exit() is called.
+}
+
+// Top-level definitions can be replaced, so mistakes can be fixed
+// without restarting.
+func newSession() *session {
+	return &session{scope: RedefinableScope(notifyRedefined), checker: checker.New()}
+}
 
+func notifyRedefined(id string) {
+	fmt.Printf("Redefined %s.\n", id)
+}
+
+// Parses and executes the code, printing the result.
+func (s *session) eval(code string) {
+	expressions, err := Parse(Tokenise(code))
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	// Each expression is only given to the checker once it has executed,
+	// so it knows the same definitions as the scope.
+	var result EvalResult
+	for _, expr := range expressions {
+		result = ExecuteIn([]Expression{expr}, s.scope)
 		if result.Error != nil {
-			fmt.Println(result.Error.Error())
+			break
 		}
 
-		if result.Value != nil {
-			fmt.Println(result.Value.String())
+		s.checker.Infer([]Expression{expr})
+	}
+
+	if exit, ok := result.Error.(ExitError); ok {
+		s.exit = &exit
+		return
+	}
+
+	if result.Error != nil {
+		fmt.Println(result.Error.Error())
+	}
+
+	if result.Value != nil {
+		fmt.Println(result.Value.String())
+	}
+}
+
+// Reads lines until the brackets, blocks and strings are closed.
+func readInput(line *liner.State) (string, error) {
+	text, err := line.Prompt(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	for !isComplete(text) {
+		more, err := line.Prompt(continuationPrompt)
+		if err != nil {
+			return "", err
 		}
+
+		text += "\n" + more
 	}
+
+	return text, nil
 }
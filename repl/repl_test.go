@@ -0,0 +1,38 @@
+package repl
+
+import (
+	. "github.com/jonnyarnold/fn-go/compiler/parser"
+	"github.com/jonnyarnold/fn-go/compiler/tokeniser"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Returns the type the session's checker gives the code.
+func typeIn(s *session, code string) string {
+	exprs, err := Parse(tokeniser.Tokenise(code))
+	if err != nil {
+		panic(err)
+	}
+
+	t, _ := s.checker.Copy().Infer(exprs)
+	return t.String()
+}
+
+func TestSession(t *testing.T) {
+	Convey("eval", t, func() {
+		s := newSession()
+
+		Convey("gives the checker the definitions that executed", func() {
+			s.eval("x = 1; y = notDefined")
+
+			So(typeIn(s, "x"), ShouldEqual, "Number")
+			So(typeIn(s, "y"), ShouldEqual, "Any")
+		})
+
+		Convey("does not give the checker definitions made by :type", func() {
+			s.runCommand(":type z = 1")
+
+			So(typeIn(s, "z"), ShouldEqual, "Any")
+		})
+	})
+}